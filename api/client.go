@@ -0,0 +1,186 @@
+// Package api is the client for the PolyField results server.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type EventRules struct {
+	Attempts        int  `json:"attempts"`
+	CutEnabled      bool `json:"cutEnabled"`
+	CutQualifiers   int  `json:"cutQualifiers"`
+	ReorderAfterCut bool `json:"reorderAfterCut"`
+}
+type Athlete struct {
+	Bib   string `json:"bib"`
+	Order int    `json:"order"`
+	Name  string `json:"name"`
+	Club  string `json:"club"`
+	// Age on the day of competition, needed for age grading.
+	Age int `json:"age,omitempty"`
+	// PB and SB are the athlete's personal and season bests in this event.
+	PB string `json:"pb,omitempty"`
+	SB string `json:"sb,omitempty"`
+}
+type Event struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Rules    EventRules `json:"rules,omitempty"`
+	Athletes []Athlete  `json:"athletes,omitempty"`
+	Flights  []Flight   `json:"flights,omitempty"`
+	// Combined is set when the event is part of a decathlon or heptathlon.
+	Combined *CombinedEvent `json:"combined,omitempty"`
+	// AgeGroup is e.g. "U17", "Senior" or a masters group such as "M50".
+	AgeGroup  string     `json:"ageGroup,omitempty"`
+	Implement *Implement `json:"implement,omitempty"`
+	Records   []Record   `json:"records,omitempty"`
+}
+
+// Record is a standing best for the event: Kind is club, championship or
+// meeting.
+type Record struct {
+	Kind   string `json:"kind"`
+	Mark   string `json:"mark"`
+	Holder string `json:"holder,omitempty"`
+}
+
+// Implement is the throwing implement an event uses: SHOT, DISCUS, HAMMER or
+// JAVELIN, with its weight in kilograms.
+type Implement struct {
+	Type   string  `json:"type"`
+	Weight float64 `json:"weight"`
+}
+
+// CombinedEvent places an event in a combined-events competition: the
+// competition it scores towards, the scoring tables (M or W) and the
+// discipline (LJ, SP, HJ, DT, PV or JT).
+type CombinedEvent struct {
+	Competition string `json:"competition"`
+	Sex         string `json:"sex"`
+	Discipline  string `json:"discipline"`
+}
+
+// Flight is a pool of athletes that compete together, listed in throwing
+// order.
+type Flight struct {
+	Name string   `json:"name"`
+	Bibs []string `json:"bibs"`
+}
+
+// Performance is one attempt. Status refines Valid (see AttemptStatus); Rule
+// cites the rule behind a disqualification or card.
+type Performance struct {
+	Attempt int           `json:"attempt"`
+	Mark    string        `json:"mark"`
+	Unit    string        `json:"unit"`
+	Wind    *string       `json:"wind,omitempty"`
+	Valid   bool          `json:"valid"`
+	Status  AttemptStatus `json:"status,omitempty"`
+	Rule    string        `json:"rule,omitempty"`
+	Card    string        `json:"card,omitempty"`
+	// Flags marks a valid attempt that set a PB, SB or record.
+	Flags []string `json:"flags,omitempty"`
+}
+
+// HeightRecord is one bar height in a vertical jump. Attempts holds up to
+// three of O (cleared), X (failed) and - (passed), in order.
+type HeightRecord struct {
+	Height   string `json:"height"`
+	Attempts string `json:"attempts"`
+	JumpOff  bool   `json:"jumpOff,omitempty"`
+}
+type ResultPayload struct {
+	EventID    string         `json:"eventId"`
+	AthleteBib string         `json:"athleteBib"`
+	Series     []Performance  `json:"series"`
+	Heights    []HeightRecord `json:"heights,omitempty"`
+	// Points and TotalPoints are filled in for combined events.
+	Points      int `json:"points,omitempty"`
+	TotalPoints int `json:"totalPoints,omitempty"`
+	// AgeGraded is the WMA age-graded percentage for masters events.
+	AgeGraded float64 `json:"ageGraded,omitempty"`
+}
+
+// StatusError is returned when the server answered but did not accept the
+// request; any other error means it could not be reached.
+type StatusError struct{ Status string }
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server returned non-200 status: %s", e.Status)
+}
+
+type Client struct {
+	HTTP *http.Client
+}
+
+func NewClient(timeout time.Duration) *Client {
+	return &Client{HTTP: &http.Client{Timeout: timeout}}
+}
+
+// FetchEvents lists the events on the server at host ("ip:port").
+func (c *Client) FetchEvents(host string) ([]Event, error) {
+	var events []Event
+	if err := c.getJSON(fmt.Sprintf("http://%s/api/v1/events", host), "event list", &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+func (c *Client) FetchEventDetails(host, eventID string) (*Event, error) {
+	var event Event
+	if err := c.getJSON(fmt.Sprintf("http://%s/api/v1/events/%s", host, eventID), "event details", &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+func (c *Client) getJSON(url, what string, v interface{}) error {
+	resp, err := c.HTTP.Get(url)
+	if err != nil {
+		return &connectError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Status: resp.Status}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", what, err)
+	}
+	return nil
+}
+func (c *Client) PostResult(host string, payload ResultPayload) error {
+	return c.sendJSON("POST", fmt.Sprintf("http://%s/api/v1/results", host), "result payload", payload)
+}
+
+// PutFlights stores the flight assignment for an event on the server.
+func (c *Client) PutFlights(host, eventID string, flights []Flight) error {
+	return c.sendJSON("PUT", fmt.Sprintf("http://%s/api/v1/events/%s/flights", host, eventID), "flights", flights)
+}
+func (c *Client) sendJSON(method, url, what string, v interface{}) error {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return &connectError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Status: resp.Status}
+	}
+	return nil
+}
+
+type connectError struct{ err error }
+
+func (e *connectError) Error() string { return fmt.Sprintf("failed to connect to server: %v", e.err) }
+func (e *connectError) Unwrap() error { return e.err }
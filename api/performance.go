@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// AttemptStatus says what an attempt was. Payloads without one are read from
+// Valid, so older clients keep their meaning.
+type AttemptStatus string
+
+const (
+	AttemptValid     AttemptStatus = "valid"
+	AttemptFoul      AttemptStatus = "foul"
+	AttemptTimeLimit AttemptStatus = "timeLimit"
+	AttemptPass      AttemptStatus = "pass"
+	AttemptRetired   AttemptStatus = "retired"
+	AttemptDNS       AttemptStatus = "dns"
+	AttemptDQ        AttemptStatus = "dq"
+
+	CardYellow = "yellow"
+	CardRed    = "red"
+)
+
+// attemptCodes are the marks written on the card for each status.
+var attemptCodes = map[AttemptStatus]string{
+	AttemptFoul:      "X",
+	AttemptTimeLimit: "X",
+	AttemptPass:      "-",
+	AttemptRetired:   "r",
+	AttemptDNS:       "DNS",
+	AttemptDQ:        "DQ",
+}
+
+// Effective is the attempt status, read from Valid when none is set.
+func (p Performance) Effective() AttemptStatus {
+	if p.Status != "" {
+		return p.Status
+	}
+	if p.Valid {
+		return AttemptValid
+	}
+	return AttemptFoul
+}
+
+// Code is what the results card shows: the mark, or the status code.
+func (p Performance) Code() string {
+	if code, ok := attemptCodes[p.Effective()]; ok {
+		return code
+	}
+	return p.Mark
+}
+
+// Taken reports whether the attempt has been made. The UI sends rounds still
+// to come as placeholders with neither a mark nor a status.
+func (p Performance) Taken() bool {
+	return p.Status != "" || strings.TrimSpace(p.Mark) != ""
+}
+
+// Validate checks an attempt on its own. Attempts not yet taken pass.
+func (p Performance) Validate() error {
+	if !p.Taken() {
+		return nil
+	}
+	status := p.Effective()
+	if _, ok := attemptCodes[status]; !ok && status != AttemptValid {
+		return fmt.Errorf("attempt %d: unknown status '%s'", p.Attempt, status)
+	}
+	if status == AttemptValid {
+		v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(p.Mark), "m")), 64)
+		if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("attempt %d: valid attempt needs a mark, got '%s'", p.Attempt, p.Mark)
+		}
+	}
+	if p.Valid != (status == AttemptValid) {
+		return fmt.Errorf("attempt %d: valid is %t but status is %s", p.Attempt, p.Valid, status)
+	}
+	switch p.Card {
+	case "", CardYellow:
+	case CardRed:
+		if status != AttemptDQ {
+			return fmt.Errorf("attempt %d: a red card disqualifies", p.Attempt)
+		}
+	default:
+		return fmt.Errorf("attempt %d: unknown card '%s'", p.Attempt, p.Card)
+	}
+	if (status == AttemptDQ || p.Card != "") && p.Rule == "" {
+		return fmt.Errorf("attempt %d: a disqualification or card needs a rule reference", p.Attempt)
+	}
+	return nil
+}
+
+// ValidateSeries checks each attempt and that nothing follows an attempt
+// that ends the athlete's competition. Placeholders for attempts not yet
+// taken are skipped.
+func ValidateSeries(series []Performance) error {
+	var prev *Performance
+	for i, p := range series {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if !p.Taken() {
+			continue
+		}
+		if prev != nil {
+			switch status := prev.Effective(); status {
+			case AttemptRetired, AttemptDNS, AttemptDQ:
+				return fmt.Errorf("attempt %d: recorded after %s", p.Attempt, status)
+			}
+			if p.Effective() == AttemptDNS {
+				return fmt.Errorf("attempt %d: DNS can only be the first entry", p.Attempt)
+			}
+		}
+		prev = &series[i]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package api
+
+import "testing"
+
+func TestValidateSeries(t *testing.T) {
+	valid := Performance{Attempt: 1, Mark: "12.34", Valid: true}
+	foul := Performance{Attempt: 2, Mark: "FOUL"}
+	for name, tc := range map[string]struct {
+		series []Performance
+		ok     bool
+	}{
+		"legacy valid and foul": {[]Performance{valid, foul}, true},
+		"pass then retired":     {[]Performance{{Attempt: 1, Status: AttemptPass}, {Attempt: 2, Status: AttemptRetired}}, true},
+		"dq with rule":          {[]Performance{valid, {Attempt: 2, Status: AttemptDQ, Card: CardRed, Rule: "7.1"}}, true},
+		"yellow card":           {[]Performance{{Attempt: 1, Mark: "FOUL", Card: CardYellow, Rule: "7.1"}}, true},
+		"time limit":            {[]Performance{{Attempt: 1, Status: AttemptTimeLimit}}, true},
+		"unknown status":        {[]Performance{{Attempt: 1, Status: "late"}}, false},
+		"valid without mark":    {[]Performance{{Attempt: 1, Status: AttemptValid, Valid: true}}, false},
+		"valid flag disagrees":  {[]Performance{{Attempt: 1, Mark: "12.00", Status: AttemptPass, Valid: true}}, false},
+		"dq without rule":       {[]Performance{{Attempt: 1, Status: AttemptDQ}}, false},
+		"red card not dq":       {[]Performance{{Attempt: 1, Mark: "FOUL", Card: CardRed, Rule: "7.1"}}, false},
+		"attempt after retired": {[]Performance{{Attempt: 1, Status: AttemptRetired}, valid}, false},
+		"dns after attempt":     {[]Performance{foul, {Attempt: 2, Status: AttemptDNS}}, false},
+		"rounds still to come":  {[]Performance{valid, {Attempt: 2, Valid: true}, {Attempt: 3, Valid: true}}, true},
+		"retired then to come":  {[]Performance{{Attempt: 1, Status: AttemptRetired}, {Attempt: 2, Valid: true}}, true},
+		"to come then dns":      {[]Performance{{Attempt: 1, Valid: true}, {Attempt: 2, Status: AttemptDNS}}, true},
+	} {
+		if err := ValidateSeries(tc.series); (err == nil) != tc.ok {
+			t.Errorf("%s: ValidateSeries = %v", name, err)
+		}
+	}
+}
+
+func TestPerformanceCode(t *testing.T) {
+	for _, tc := range []struct {
+		p    Performance
+		want string
+	}{
+		{Performance{Mark: "12.34", Valid: true}, "12.34"},
+		{Performance{Mark: "FOUL"}, "X"},
+		{Performance{Status: AttemptTimeLimit}, "X"},
+		{Performance{Status: AttemptPass}, "-"},
+		{Performance{Status: AttemptRetired}, "r"},
+		{Performance{Status: AttemptDQ, Rule: "x"}, "DQ"},
+	} {
+		if got := tc.p.Code(); got != tc.want {
+			t.Errorf("%+v: Code() = %q, want %q", tc.p, got, tc.want)
+		}
+	}
+}
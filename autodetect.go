@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"go.bug.st/serial"
+	"go.bug.st/serial/enumerator"
+
+	"PolyField/devices"
+)
+
+// --- Device Auto-Detection ---
+const (
+	ConfidenceHigh = devices.ConfidenceHigh
+	ConfidenceLow  = devices.ConfidenceLow
+)
+
+type PortSuggestion = devices.PortSuggestion
+
+func (a *App) identifyCandidates() []devices.Candidate {
+	a.stateMux.Lock()
+	profiles := make([]DeviceProfile, 0, len(a.profiles))
+	for _, p := range a.profiles {
+		profiles = append(profiles, p)
+	}
+	a.stateMux.Unlock()
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	var candidates []devices.Candidate
+	seen := make(map[string]bool)
+	add := func(c devices.Candidate) {
+		c.Config = c.Config.WithDefaults()
+		key := fmt.Sprintf("%s|%+v", c.DevType, c.Config)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		candidates = append(candidates, c)
+	}
+	// Saved profiles go first: they describe hardware the club actually owns.
+	for _, p := range profiles {
+		if _, ok := devices.Drivers[p.DevType]; ok {
+			add(devices.Candidate{DevType: p.DevType, Profile: p.Name, Config: p.Serial})
+		}
+	}
+	for _, devType := range []string{"edm", "wind"} {
+		for _, baud := range devices.AutoDetectBaudRates {
+			cfg := DefaultSerialConfig()
+			cfg.BaudRate = baud
+			add(devices.Candidate{DevType: devType, Config: cfg})
+		}
+	}
+	return candidates
+}
+
+func (a *App) AutoDetectDevices() ([]PortSuggestion, error) {
+	details, err := enumerator.GetDetailedPortsList()
+	if err != nil {
+		// Not every OS supports detailed enumeration; fall back to plain names.
+		names, listErr := serial.GetPortsList()
+		if listErr != nil {
+			return nil, listErr
+		}
+		details = make([]*enumerator.PortDetails, 0, len(names))
+		for _, name := range names {
+			details = append(details, &enumerator.PortDetails{Name: name})
+		}
+	}
+	a.devicesMux.RLock()
+	inUse := make(map[string]bool)
+	for _, dev := range a.devices {
+		if dev.ConnectionType == "serial" {
+			inUse[dev.Address] = true
+		}
+	}
+	a.devicesMux.RUnlock()
+	candidates := a.identifyCandidates()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	suggestions := make([]PortSuggestion, 0, len(details))
+	for _, d := range details {
+		if inUse[d.Name] {
+			continue
+		}
+		wg.Add(1)
+		go func(d *enumerator.PortDetails) {
+			defer wg.Done()
+			s, err := devices.ProbePort(d.Name, candidates)
+			if err != nil {
+				log.Printf("Auto-detect skipped %s: %v", d.Name, err)
+				return
+			}
+			s.IsUSB, s.VID, s.PID, s.SerialNumber, s.Product = d.IsUSB, d.VID, d.PID, d.SerialNumber, d.Product
+			mu.Lock()
+			suggestions = append(suggestions, *s)
+			mu.Unlock()
+		}(d)
+	}
+	wg.Wait()
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence == ConfidenceHigh
+		}
+		return suggestions[i].Port < suggestions[j].Port
+	})
+	return suggestions, nil
+}
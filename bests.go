@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"PolyField/competition"
+)
+
+// --- Bests & Records ---
+// Athletes' PB and SB and the event records come with the event details or
+// from a local file. Posted series are flagged attempt by attempt, and a
+// fresh measurement for the athlete on the overlay is flagged on the
+// scoreboard and overlay as soon as it is taken.
+
+// LoadBestsFile reads prior performances (see README), adding to any bests
+// already known.
+func (a *App) LoadBestsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	byEvent, err := competition.LoadBests(f)
+	if err != nil {
+		return err
+	}
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	for eventID, loaded := range byEvent {
+		eb := a.eventBests(eventID)
+		for _, r := range loaded.Records {
+			eb.SetRecord(r)
+		}
+		for bib, b := range loaded.Athletes {
+			eb.Athletes[bib] = b
+		}
+	}
+	return nil
+}
+func (a *App) registerBests(event Event) {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	if err := a.eventBests(event.ID).Merge(event); err != nil {
+		log.Printf("Event %s: %v", event.ID, err)
+	}
+}
+
+// eventBests must be called with competitionMux held.
+func (a *App) eventBests(eventID string) *competition.EventBests {
+	eb, ok := a.bests[eventID]
+	if !ok {
+		eb = competition.NewEventBests()
+		a.bests[eventID] = eb
+	}
+	return eb
+}
+
+// FlagMark lists the PB, SB and record flags a new mark for bib would earn,
+// given the series posted so far.
+func (a *App) FlagMark(eventID, bib, mark string) ([]string, error) {
+	cm, ok := competition.ParseMark(mark)
+	if !ok {
+		return nil, fmt.Errorf("invalid mark '%s'", mark)
+	}
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	eb, ok := a.bests[eventID]
+	if !ok {
+		return []string{}, nil
+	}
+	return eb.Flags(bib, cm, a.postedSeries(eventID, bib)), nil
+}
+
+// flagResult flags each new attempt in the payload's series; records broken
+// are raised for the rest of the meeting.
+func (a *App) flagResult(payload *ResultPayload) {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	eb, ok := a.bests[payload.EventID]
+	if !ok {
+		return
+	}
+	payload.Series = eb.FlagSeries(payload.AthleteBib, payload.Series, a.postedSeries(payload.EventID, payload.AthleteBib))
+}
+
+// postedSeries must be called with competitionMux held.
+func (a *App) postedSeries(eventID, bib string) []Performance {
+	if he, ok := a.horizontals[eventID]; ok {
+		for _, e := range he.entries {
+			if e.Bib == bib {
+				return e.Series
+			}
+		}
+	}
+	return nil
+}
+
+// liveFlags flags a mark measured by deviceID for the athlete it is
+// measuring; with no athlete known there is nothing to flag.
+func (a *App) liveFlags(deviceID, mark string) []string {
+	eventID, bib := a.measuredAthlete(deviceID)
+	if bib == "" {
+		return nil
+	}
+	flags, err := a.FlagMark(eventID, bib, strings.TrimSuffix(mark, " m"))
+	if err != nil {
+		return nil
+	}
+	return flags
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResultsAreFlaggedAgainstBests(t *testing.T) {
+	event := Event{ID: "dt-w", Athletes: []Athlete{{Bib: "5", Order: 1, PB: "50.00", SB: "48.00"}, {Bib: "6", Order: 2}},
+		Records: []Record{{Kind: "meeting", Mark: "52.00"}}}
+	srv := newFakePolyFieldServer(t, []Event{event})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	if _, err := a.FetchEventDetails(host, port, "dt-w"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bests.json")
+	file := `{"athletes": [{"eventId": "dt-w", "bib": "6", "sb": "40.00"}], "records": [{"eventId": "dt-w", "kind": "club", "mark": "55.00"}]}`
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.LoadBestsFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	series := []Performance{{Attempt: 1, Mark: "49.00", Unit: "m", Valid: true}, {Attempt: 2, Mark: "52.50", Unit: "m", Valid: true}}
+	if err := a.PostResult(host, port, ResultPayload{EventID: "dt-w", AthleteBib: "5", Series: series}); err != nil {
+		t.Fatal(err)
+	}
+	if series[0].Flags != nil {
+		t.Fatal("caller's series was modified")
+	}
+	got := srv.results()[0].Series
+	if !reflect.DeepEqual(got[0].Flags, []string{"SB"}) || !reflect.DeepEqual(got[1].Flags, []string{"MR", "PB"}) {
+		t.Fatalf("flags %v, %v", got[0].Flags, got[1].Flags)
+	}
+	// The next mark must beat 52.50 to be a new best.
+	if flags, _ := a.FlagMark("dt-w", "5", "52.10"); len(flags) != 0 {
+		t.Fatalf("flags for a shorter throw: %v", flags)
+	}
+	if flags, _ := a.FlagMark("dt-w", "6", "56.00"); !reflect.DeepEqual(flags, []string{"MR", "CLR", "SB"}) {
+		t.Fatalf("flags from the file: %v", flags)
+	}
+}
+
+func TestLiveFlagsFollowTheMeasuringStation(t *testing.T) {
+	a := newTestApp(t)
+	for _, event := range []Event{
+		{ID: "sp-a", Athletes: []Athlete{{Bib: "1", Order: 1, PB: "10.00"}}},
+		{ID: "sp-b", Athletes: []Athlete{{Bib: "2", Order: 1, PB: "20.00"}}},
+	} {
+		if err := a.registerEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.SetOverlayEvent(Event{ID: "sp-a"})
+	a.SetOverlayAthlete("1", 1)
+	if err := a.SaveStation(Station{Name: "Circle B", EDM: "edm-b", EventID: "sp-b"}); err != nil {
+		t.Fatal(err)
+	}
+	if flags := a.liveFlags("edm-b", "15.00 m"); flags != nil {
+		t.Fatalf("circle B flagged against the overlay athlete: %v", flags)
+	}
+	if err := a.SetStationAthlete("Circle B", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if flags := a.liveFlags("edm-b", "15.00 m"); len(flags) != 0 {
+		t.Fatalf("flags below bib 2's PB: %v", flags)
+	}
+	if flags := a.liveFlags("edm-b", "21.00 m"); !reflect.DeepEqual(flags, []string{"PB"}) {
+		t.Fatalf("circle B flags %v", flags)
+	}
+	if flags := a.liveFlags("edm", "15.00 m"); !reflect.DeepEqual(flags, []string{"PB"}) {
+		t.Fatalf("overlay athlete flags %v", flags)
+	}
+}
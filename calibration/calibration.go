@@ -0,0 +1,106 @@
+// Package calibration keeps each EDM's circle calibration: where the
+// instrument stands relative to the circle centre and whether the circle
+// edge checked out.
+package calibration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"PolyField/measurement"
+)
+
+type Data struct {
+	DeviceID               string
+	Timestamp              time.Time
+	SelectedCircleType     string
+	TargetRadius           float64
+	StationCoordinates     measurement.Point
+	IsCentreSet            bool
+	EdgeVerificationResult *measurement.EdgeVerificationResult
+}
+
+// Default is the calibration of an EDM nobody has set up yet.
+func Default(deviceID string) Data {
+	return Data{DeviceID: deviceID, SelectedCircleType: "SHOT", TargetRadius: measurement.UkaRadiusShot}
+}
+
+// WithCircle switches to another circle type. The station stays valid but
+// the edge has to be checked again against the new radius.
+func (d Data) WithCircle(circleType string) (Data, error) {
+	radius, ok := measurement.CircleRadii[circleType]
+	if !ok {
+		return d, fmt.Errorf("unknown circle type '%s'", circleType)
+	}
+	d.SelectedCircleType = circleType
+	d.TargetRadius = radius
+	d.EdgeVerificationResult = nil
+	return d, nil
+}
+
+// WithCentre records a reading taken on the circle centre. Any earlier edge
+// check no longer applies.
+func (d Data) WithCentre(r measurement.AveragedReading, now time.Time) Data {
+	d.StationCoordinates = measurement.StationFromCentre(r)
+	d.IsCentreSet = true
+	d.EdgeVerificationResult = nil
+	d.Timestamp = now.UTC()
+	return d
+}
+func (d Data) WithEdge(r measurement.AveragedReading) Data {
+	res := measurement.VerifyEdge(d.StationCoordinates, d.TargetRadius, d.SelectedCircleType, r)
+	d.EdgeVerificationResult = &res
+	return d
+}
+func (d Data) Throw(r measurement.AveragedReading) float64 {
+	return measurement.ThrowDistance(d.StationCoordinates, d.TargetRadius, r)
+}
+
+// Store holds calibrations by device. Entries are replaced, never modified,
+// so values handed out can be read without the lock.
+type Store struct {
+	mu sync.Mutex
+	m  map[string]*Data
+}
+
+func NewStore() *Store { return &Store{m: make(map[string]*Data)} }
+
+func (s *Store) Get(deviceID string) (Data, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.m[deviceID]
+	if !ok {
+		return Data{}, false
+	}
+	return *d, true
+}
+
+// GetOrDefault returns the stored calibration or Default.
+func (s *Store) GetOrDefault(deviceID string) Data {
+	if d, ok := s.Get(deviceID); ok {
+		return d
+	}
+	return Default(deviceID)
+}
+func (s *Store) Save(d Data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[d.DeviceID] = &d
+}
+func (s *Store) Reset(deviceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, deviceID)
+}
+
+// All returns every stored calibration keyed by device.
+func (s *Store) All() map[string]Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make(map[string]Data, len(s.m))
+	for id, d := range s.m {
+		all[id] = *d
+	}
+	return all
+}
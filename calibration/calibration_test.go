@@ -0,0 +1,31 @@
+package calibration
+
+import (
+	"testing"
+	"time"
+
+	"PolyField/measurement"
+)
+
+func TestWithCentreClearsEdgeCheck(t *testing.T) {
+	d := Default("edm")
+	d.EdgeVerificationResult = &measurement.EdgeVerificationResult{}
+	d = d.WithCentre(measurement.AveragedReading{SlopeDistanceMm: 5000, VAzDecimal: 90, HARDecimal: 0}, time.Now())
+	if !d.IsCentreSet || d.EdgeVerificationResult != nil {
+		t.Fatalf("after WithCentre: %+v", d)
+	}
+}
+
+func TestStoreReturnsCopies(t *testing.T) {
+	s := NewStore()
+	s.Save(Default("edm"))
+	d, _ := s.Get("edm")
+	d.IsCentreSet = true
+	if got := s.GetOrDefault("edm"); got.IsCentreSet {
+		t.Fatal("modifying a returned calibration changed the store")
+	}
+	s.Reset("edm")
+	if _, ok := s.Get("edm"); ok {
+		t.Fatal("Reset left the calibration in place")
+	}
+}
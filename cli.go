@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// --- Command Line ---
+// With a subcommand the binary runs headless: "daemon" starts the API server
+// and every other subcommand is a client of a running daemon.
+const cliTimeout = 30 * time.Second
+
+const cliUsage = `usage: polyfield [-daemon host:port] [-token token] <command>
+
+commands:
+  daemon [-listen host:port] [-demo] [-token token]
+                                         run headless and serve the local API
+  devices list | ports | status          connected devices, serial ports, health
+  edm|wind|scoreboard connect [-device id] [-profile name] <serial-port|host:port>
+  edm|wind|scoreboard disconnect [-device id]
+  calibrate circle [-device id] <SHOT|DISCUS|HAMMER|JAVELIN_ARC>
+  calibrate centre|edge [-device id]
+  measure [-device id]                   measure a throw
+  wind [-device id]                      average wind over the last 5 s
+  queue status                           results waiting for the server
+`
+
+// cliCommands are the subcommands that select the command line.
+var cliCommands = map[string]bool{"daemon": true, "devices": true, "edm": true, "wind": true, "scoreboard": true, "calibrate": true, "measure": true, "queue": true}
+
+// isCLICommand reports whether the arguments name a subcommand, after any
+// global flags, or ask for help. Anything else, such as the -psn_ argument
+// macOS passes to apps opened from Finder, leaves the window to start.
+func isCLICommand(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "="); {
+		case !strings.HasPrefix(arg, "-"):
+			return cliCommands[arg]
+		case name == "h" || name == "help":
+			return true
+		case name == "daemon" || name == "token":
+			if !hasValue {
+				i++
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+type cliClient struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("polyfield", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, cliUsage) }
+	addr := os.Getenv("POLYFIELD_DAEMON")
+	if addr == "" {
+		addr = defaultDaemonAddress
+	}
+	global.StringVar(&addr, "daemon", addr, "address of the PolyField daemon")
+	token := global.String("token", os.Getenv("POLYFIELD_TOKEN"), "daemon API token")
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+	c := &cliClient{base: "http://" + addr, token: *token, http: &http.Client{Timeout: cliTimeout}}
+	if err := c.run(global.Args(), stdout); err != nil {
+		fmt.Fprintf(stderr, "polyfield: %v\n", err)
+		if _, ok := err.(usageError); ok {
+			fmt.Fprint(stderr, cliUsage)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func (c *cliClient) run(args []string, out io.Writer) error {
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "daemon":
+		fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+		listen := fs.String("listen", defaultDaemonAddress, "address to serve the API on")
+		demo := fs.Bool("demo", false, "use simulated hardware")
+		token := fs.String("token", c.token, "require this token on every request")
+		if err := fs.Parse(rest); err != nil {
+			return usageError(err.Error())
+		}
+		return runDaemon(*listen, *demo, *token)
+	case "devices":
+		return c.devices(rest, out)
+	case "edm", "scoreboard":
+		return c.connection(cmd, rest, out)
+	case "wind":
+		if len(rest) > 0 && (rest[0] == "connect" || rest[0] == "disconnect") {
+			return c.connection(cmd, rest, out)
+		}
+		return c.wind(rest, out)
+	case "calibrate":
+		return c.calibrate(rest, out)
+	case "measure":
+		device, _, err := parseDeviceFlag("measure", "edm", rest)
+		if err != nil {
+			return err
+		}
+		var res markResponse
+		if err := c.call("POST", "/api/v1/edm/"+device+"/measure", nil, &res); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, res.Mark)
+		return nil
+	case "queue":
+		if len(rest) != 1 || rest[0] != "status" {
+			return usageError("usage: queue status")
+		}
+		var q queueStatus
+		if err := c.call("GET", "/api/v1/queue", nil, &q); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d result(s) waiting for the server\n", q.Pending)
+		for _, r := range q.Results {
+			fmt.Fprintf(out, "  event %s  bib %s  %d attempt(s)\n", r.EventID, r.AthleteBib, len(r.Series))
+		}
+		return nil
+	}
+	return usageError(fmt.Sprintf("unknown command '%s'", cmd))
+}
+
+func (c *cliClient) devices(args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return usageError("usage: devices list | ports | status")
+	}
+	switch args[0] {
+	case "list":
+		var infos []DeviceInfo
+		if err := c.call("GET", "/api/v1/devices", nil, &infos); err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTYPE\tCONNECTION\tADDRESS\tSTATION\tCALIBRATED")
+		for _, d := range infos {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\n", d.ID, d.Type, d.ConnectionType, d.Address, d.Station, d.Calibrated)
+		}
+		return tw.Flush()
+	case "ports":
+		var ports []string
+		if err := c.call("GET", "/api/v1/ports", nil, &ports); err != nil {
+			return err
+		}
+		for _, p := range ports {
+			fmt.Fprintln(out, p)
+		}
+		return nil
+	case "status":
+		var statuses map[string]DeviceHealth
+		if err := c.call("GET", "/api/v1/status", nil, &statuses); err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		ids := make([]string, 0, len(statuses))
+		for id := range statuses {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		fmt.Fprintln(tw, "ID\tSTATUS\tLATENCY\tLAST ERROR")
+		for _, id := range ids {
+			h := statuses[id]
+			fmt.Fprintf(tw, "%s\t%s\t%.0f ms\t%s\n", id, h.Status, h.LatencyMs, h.LastError)
+		}
+		return tw.Flush()
+	}
+	return usageError(fmt.Sprintf("unknown devices command '%s'", args[0]))
+}
+
+// connection handles "<type> connect" and "<type> disconnect". The target is
+// a network address when it parses as host:port, otherwise a serial port.
+func (c *cliClient) connection(devType string, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return usageError(fmt.Sprintf("usage: %s connect|disconnect", devType))
+	}
+	fs := flag.NewFlagSet(devType+" "+args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	device := fs.String("device", devType, "device name")
+	profile := fs.String("profile", "", "serial profile")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError(err.Error())
+	}
+	var msg statusMessage
+	switch {
+	case args[0] == "disconnect" && fs.NArg() == 0:
+		if err := c.call("POST", "/api/v1/devices/"+*device+"/disconnect", nil, &msg); err != nil {
+			return err
+		}
+	case args[0] == "connect" && fs.NArg() == 1:
+		req := connectRequest{Port: fs.Arg(0), Profile: *profile}
+		if _, _, err := net.SplitHostPort(fs.Arg(0)); err == nil {
+			if *profile != "" {
+				return usageError("-profile applies to serial ports, not " + fs.Arg(0))
+			}
+			req = connectRequest{Address: fs.Arg(0)}
+		}
+		if err := c.call("POST", "/api/v1/devices/"+*device+"/connect", req, &msg); err != nil {
+			return err
+		}
+	default:
+		return usageError(fmt.Sprintf("usage: %s connect [-device id] [-profile name] <serial-port|host:port>", devType))
+	}
+	fmt.Fprintln(out, msg.Message)
+	return nil
+}
+
+func (c *cliClient) calibrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return usageError("usage: calibrate circle|centre|edge")
+	}
+	device, rest, err := parseDeviceFlag("calibrate "+args[0], "edm", args[1:])
+	if err != nil {
+		return err
+	}
+	var cal EDMCalibrationData
+	switch {
+	case args[0] == "circle" && len(rest) == 1:
+		if err := c.call("POST", "/api/v1/edm/"+device+"/circle", circleRequest{CircleType: rest[0]}, &cal); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: %s circle, radius %.4f m\n", device, cal.SelectedCircleType, cal.TargetRadius)
+	case args[0] == "centre" && len(rest) == 0:
+		if err := c.call("POST", "/api/v1/edm/"+device+"/centre", nil, &cal); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: centre set, instrument at X=%.3f m Y=%.3f m from the centre\n", device, cal.StationCoordinates.X, cal.StationCoordinates.Y)
+	case args[0] == "edge" && len(rest) == 0:
+		if err := c.call("POST", "/api/v1/edm/"+device+"/edge", nil, &cal); err != nil {
+			return err
+		}
+		res := cal.EdgeVerificationResult
+		verdict := "OK"
+		if !res.IsInTolerance {
+			verdict = "OUT OF TOLERANCE"
+		}
+		fmt.Fprintf(out, "%s: edge at %.4f m, %+.1f mm (±%.1f mm) %s\n", device, res.MeasuredRadius, res.DifferenceMm, res.ToleranceAppliedMm, verdict)
+	default:
+		return usageError("usage: calibrate circle [-device id] <type> | calibrate centre|edge [-device id]")
+	}
+	return nil
+}
+
+func (c *cliClient) wind(args []string, out io.Writer) error {
+	device, rest, err := parseDeviceFlag("wind", "wind", args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return usageError("usage: wind [-device id]")
+	}
+	var res windResponse
+	if err := c.call("POST", "/api/v1/wind/"+device+"/measure", nil, &res); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, res.Wind)
+	return nil
+}
+
+func parseDeviceFlag(name, def string, args []string) (string, []string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	device := fs.String("device", def, "device name")
+	if err := fs.Parse(args); err != nil {
+		return "", nil, usageError(err.Error())
+	}
+	return *device, fs.Args(), nil
+}
+
+// call sends body as JSON and decodes the answer into out, turning an API
+// error reply into a Go error.
+func (c *cliClient) call(method, path string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.base+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach the daemon at %s (is 'polyfield daemon' running?): %w", c.base, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("daemon returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"PolyField/simulator"
+)
+
+func runTestCLI(t *testing.T, daemon string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCLI(append([]string{"-daemon", daemon}, args...), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCLIMeasuresThroughDaemon(t *testing.T) {
+	a, demo := newDemoApp(t)
+	cfg := simulator.DefaultEDMConfig()
+	cfg.NoiseMm = 0
+	demo.edms["edm"].SetConfig(cfg)
+	demo.throwDistance = func(string, *rand.Rand) float64 { return 12.34 }
+	srv := httptest.NewServer(a.apiHandler())
+	defer srv.Close()
+	daemon := srv.Listener.Addr().String()
+
+	if _, stderr, code := runTestCLI(t, daemon, "measure"); code != 1 || !strings.Contains(stderr, "not calibrated") {
+		t.Fatalf("measure before calibration: code %d, stderr %q", code, stderr)
+	}
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"calibrate", "circle", "SHOT"}, "radius 1.0675 m"},
+		{[]string{"calibrate", "centre"}, "X=-4.000 m Y=-3.000 m"},
+		{[]string{"calibrate", "edge"}, "OK"},
+		{[]string{"measure"}, "12.34 m"},
+		{[]string{"devices", "list"}, "edm"},
+		{[]string{"queue", "status"}, "0 result(s) waiting"},
+	}
+	for _, s := range steps {
+		stdout, stderr, code := runTestCLI(t, daemon, s.args...)
+		if code != 0 || !strings.Contains(stdout, s.want) {
+			t.Fatalf("%v: code %d, stdout %q, stderr %q; want %q", s.args, code, stdout, stderr, s.want)
+		}
+	}
+}
+
+func TestCLIUsageErrors(t *testing.T) {
+	for _, args := range [][]string{{}, {"launch"}, {"calibrate", "circle"}, {"queue", "flush"}} {
+		if _, stderr, code := runTestCLI(t, "127.0.0.1:1", args...); code != 2 || !strings.Contains(stderr, "usage:") {
+			t.Errorf("%v: code %d, stderr %q", args, code, stderr)
+		}
+	}
+	if _, stderr, code := runTestCLI(t, "127.0.0.1:1", "calibrate", "circle", "ROUND"); code != 1 || !strings.Contains(stderr, "could not reach the daemon") {
+		t.Errorf("unreachable daemon: code %d, stderr %q", code, stderr)
+	}
+}
+
+func TestIsCLICommand(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-psn_0_1234567"}, false},
+		{[]string{"measure"}, true},
+		{[]string{"-daemon", "10.0.0.2:7411", "-token", "s3cret", "queue", "status"}, true},
+		{[]string{"--daemon=10.0.0.2:7411", "wind"}, true},
+		{[]string{"-h"}, true},
+		{[]string{"launch"}, false},
+	} {
+		if got := isCLICommand(tc.args); got != tc.want {
+			t.Errorf("isCLICommand(%q) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestCLIRejectsProfileForNetworkDevice(t *testing.T) {
+	_, stderr, code := runTestCLI(t, "127.0.0.1:1", "edm", "connect", "-profile", "leica", "192.168.1.20:4001")
+	if code != 2 || !strings.Contains(stderr, "-profile applies to serial ports") {
+		t.Fatalf("code %d, stderr %q", code, stderr)
+	}
+}
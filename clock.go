@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"PolyField/competition"
+	"PolyField/devices"
+)
+
+// --- Attempt Clock ---
+// Each event has one clock, started when an athlete is called and stopped
+// when the attempt begins. Every second is emitted to the UI and, when a
+// scoreboard is given, queued to it. An expired clock keeps its state until
+// the next call so the official can record the time-limit foul.
+const attemptClockEvent = "attempt-clock"
+
+type AttemptClock struct {
+	EventID          string     `json:"eventId"`
+	Bib              string     `json:"bib"`
+	LimitSeconds     int        `json:"limitSeconds"`
+	RemainingSeconds int        `json:"remainingSeconds"`
+	Consecutive      bool       `json:"consecutive"`
+	Running          bool       `json:"running"`
+	StartedAt        time.Time  `json:"startedAt"`
+	ExpiredAt        *time.Time `json:"expiredAt,omitempty"`
+}
+type eventClock struct {
+	state      AttemptClock
+	scoreboard string
+	stop       chan struct{}
+}
+
+// StartAttemptClock calls bib in eventID, replacing any clock still running
+// there. The limit follows the event's discipline and how many athletes are
+// left; a second call in a row for the same athlete counts as consecutive
+// attempts. scoreboardID may be empty.
+func (a *App) StartAttemptClock(eventID, bib, scoreboardID string) (*AttemptClock, error) {
+	if bib == "" {
+		return nil, fmt.Errorf("no athlete called")
+	}
+	if scoreboardID != "" {
+		if devType, err := devices.TypeForID(scoreboardID); err != nil || devType != "scoreboard" {
+			return nil, fmt.Errorf("'%s' is not a scoreboard", scoreboardID)
+		}
+	}
+	discipline, remaining := a.clockField(eventID)
+	a.clockMux.Lock()
+	prev := a.clocks[eventID]
+	if prev != nil && prev.state.Running {
+		close(prev.stop)
+	}
+	consecutive := prev != nil && prev.state.Bib == bib
+	limit := int(competition.TimeLimit(discipline, remaining, consecutive) / time.Second)
+	c := &eventClock{
+		state: AttemptClock{EventID: eventID, Bib: bib, LimitSeconds: limit, RemainingSeconds: limit,
+			Consecutive: consecutive, Running: true, StartedAt: time.Now()},
+		scoreboard: scoreboardID,
+		stop:       make(chan struct{}),
+	}
+	a.clocks[eventID] = c
+	state := c.state
+	a.clockMux.Unlock()
+	a.publishClock(c.scoreboard, state)
+	go a.runClock(c, a.clockTick)
+	return &state, nil
+}
+
+// StopAttemptClock stops the clock as the attempt begins.
+func (a *App) StopAttemptClock(eventID string) (*AttemptClock, error) {
+	a.clockMux.Lock()
+	c, ok := a.clocks[eventID]
+	if !ok {
+		a.clockMux.Unlock()
+		return nil, fmt.Errorf("no attempt clock for event '%s'", eventID)
+	}
+	if c.state.Running {
+		close(c.stop)
+		c.state.Running = false
+	}
+	state := c.state
+	a.clockMux.Unlock()
+	a.publishClock(c.scoreboard, state)
+	return &state, nil
+}
+func (a *App) GetAttemptClock(eventID string) (*AttemptClock, error) {
+	a.clockMux.Lock()
+	defer a.clockMux.Unlock()
+	c, ok := a.clocks[eventID]
+	if !ok {
+		return nil, fmt.Errorf("no attempt clock for event '%s'", eventID)
+	}
+	state := c.state
+	return &state, nil
+}
+
+// clockField returns the event's discipline, empty for distance events, and
+// how many athletes are still competing.
+func (a *App) clockField(eventID string) (discipline string, remaining int) {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	if ve, ok := a.verticals[eventID]; ok {
+		for _, s := range ve.jump.Standings() {
+			if !s.Eliminated && !s.Retired {
+				remaining++
+			}
+		}
+		return ve.jump.Discipline, remaining
+	}
+	if he, ok := a.horizontals[eventID]; ok {
+		for _, e := range he.entries {
+			if !e.Out() {
+				remaining++
+			}
+		}
+	}
+	return "", remaining
+}
+
+// runClock counts c down once per tick until it is stopped or expires.
+func (a *App) runClock(c *eventClock, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+		a.clockMux.Lock()
+		select {
+		case <-c.stop:
+			a.clockMux.Unlock()
+			return
+		default:
+		}
+		c.state.RemainingSeconds--
+		if c.state.RemainingSeconds <= 0 {
+			now := time.Now()
+			c.state.Running = false
+			c.state.ExpiredAt = &now
+		}
+		state := c.state
+		a.clockMux.Unlock()
+		a.publishClock(c.scoreboard, state)
+		if state.ExpiredAt != nil {
+			log.Printf("Attempt clock expired for bib %s in event %s", state.Bib, state.EventID)
+			return
+		}
+	}
+}
+func (a *App) publishClock(scoreboardID string, state AttemptClock) {
+	a.emitEvent(attemptClockEvent, state)
+	if scoreboardID == "" {
+		return
+	}
+	msg := ScoreboardMessage{Bib: state.Bib, Lines: []string{fmt.Sprintf("%d:%02d", state.RemainingSeconds/60, state.RemainingSeconds%60)}}
+	if _, err := a.QueueScoreboardMessage(scoreboardID, ScoreboardKindClock, msg); err != nil {
+		log.Printf("Attempt clock not queued to %s: %v", scoreboardID, err)
+	}
+}
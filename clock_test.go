@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptClockLimitsAndExpiry(t *testing.T) {
+	a := newTestApp(t)
+	a.clockTick = time.Millisecond
+	event := Event{ID: "pv-m", Athletes: []Athlete{{Bib: "1", Order: 1}, {Bib: "2", Order: 2}, {Bib: "3", Order: 3}}}
+	if _, err := a.StartVerticalJump(event, "PV", []float64{4.00}); err != nil {
+		t.Fatal(err)
+	}
+	clock, err := a.StartAttemptClock("pv-m", "1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three left in the pole vault: two minutes.
+	if clock.LimitSeconds != 120 || clock.Consecutive || !clock.Running {
+		t.Fatalf("first call %+v", clock)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		clock, _ = a.GetAttemptClock("pv-m")
+		if clock.ExpiredAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("clock did not expire: %+v", clock)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if clock.Running || clock.RemainingSeconds != 0 {
+		t.Fatalf("expired clock %+v", clock)
+	}
+
+	a.clockTick = time.Hour
+	if clock, _ = a.StartAttemptClock("pv-m", "1", ""); clock.LimitSeconds != 180 || !clock.Consecutive {
+		t.Fatalf("consecutive call %+v", clock)
+	}
+	if clock, _ = a.StopAttemptClock("pv-m"); clock.Running || clock.ExpiredAt != nil {
+		t.Fatalf("stopped clock %+v", clock)
+	}
+	if _, err := a.StartAttemptClock("pv-m", "2", "edm-1"); err == nil {
+		t.Fatal("clock sent to a device that is not a scoreboard")
+	}
+	if _, err := a.StopAttemptClock("lj-w"); err == nil {
+		t.Fatal("stopped a clock that was never started")
+	}
+}
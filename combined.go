@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"PolyField/competition"
+)
+
+// --- Combined Events ---
+// Each posted field-event result in a decathlon or heptathlon is scored from
+// the athlete's best mark so far, and the points and running total travel
+// with the result. Totals are kept per competition and emitted as they change.
+const combinedEvent = "combined-update"
+
+type CombinedTotal struct {
+	Bib    string         `json:"bib"`
+	Points int            `json:"points"`
+	Events map[string]int `json:"events"`
+}
+type CombinedStandings struct {
+	Competition string          `json:"competition"`
+	Totals      []CombinedTotal `json:"totals"`
+}
+
+// SetCombinedEvent marks eventID as part of a combined-events competition.
+// Events fetched from the server may already say so.
+func (a *App) SetCombinedEvent(eventID string, spec CombinedEvent) error {
+	if spec.Competition == "" {
+		return fmt.Errorf("combined event needs a competition")
+	}
+	if _, err := competition.CombinedPoints(spec.Sex, spec.Discipline, 0); err != nil {
+		return err
+	}
+	a.competitionMux.Lock()
+	a.combinedEvents[eventID] = spec
+	a.competitionMux.Unlock()
+	a.saveEvent(eventID)
+	return nil
+}
+func (a *App) registerCombined(event Event) {
+	if event.Combined == nil {
+		return
+	}
+	if err := a.SetCombinedEvent(event.ID, *event.Combined); err != nil {
+		log.Printf("Ignoring combined-events settings for event %s: %v", event.ID, err)
+	}
+}
+func (a *App) GetCombinedStandings(competitionID string) CombinedStandings {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	return a.combinedStandings(competitionID)
+}
+
+// scoreCombined fills in the payload's points for a combined event.
+func (a *App) scoreCombined(payload *ResultPayload) {
+	a.competitionMux.Lock()
+	spec, ok := a.combinedEvents[payload.EventID]
+	if !ok {
+		a.competitionMux.Unlock()
+		return
+	}
+	cm, _ := competition.BestMark(payload.Series)
+	if len(payload.Heights) > 0 {
+		cm, _ = competition.BestCleared(payload.Heights)
+	}
+	points, _ := competition.CombinedPoints(spec.Sex, spec.Discipline, cm)
+	byAthlete, ok := a.combinedPoints[spec.Competition]
+	if !ok {
+		byAthlete = make(map[string]map[string]int)
+		a.combinedPoints[spec.Competition] = byAthlete
+	}
+	if byAthlete[payload.AthleteBib] == nil {
+		byAthlete[payload.AthleteBib] = make(map[string]int)
+	}
+	byAthlete[payload.AthleteBib][payload.EventID] = points
+	payload.Points = points
+	payload.TotalPoints = 0
+	for _, p := range byAthlete[payload.AthleteBib] {
+		payload.TotalPoints += p
+	}
+	standings := a.combinedStandings(spec.Competition)
+	a.competitionMux.Unlock()
+	a.emitEvent(combinedEvent, standings)
+}
+
+// liveCombined scores a mark measured by deviceID for the athlete it is
+// measuring, when the station's event is part of a combined competition.
+// The total counts the athlete's other events plus the better of this mark
+// and their best so far here; nothing is kept until the result is posted.
+func (a *App) liveCombined(deviceID, mark string) (points, total int, ok bool) {
+	eventID, bib := a.measuredAthlete(deviceID)
+	if bib == "" {
+		return 0, 0, false
+	}
+	cm, valid := competition.ParseMark(mark)
+	if !valid {
+		return 0, 0, false
+	}
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	spec, ok := a.combinedEvents[eventID]
+	if !ok {
+		return 0, 0, false
+	}
+	points, err := competition.CombinedPoints(spec.Sex, spec.Discipline, cm)
+	if err != nil {
+		return 0, 0, false
+	}
+	total = points
+	for id, p := range a.combinedPoints[spec.Competition][bib] {
+		switch {
+		case id != eventID:
+			total += p
+		case p > points:
+			total += p - points
+		}
+	}
+	return points, total, true
+}
+
+// combinedStandings must be called with competitionMux held.
+func (a *App) combinedStandings(competitionID string) CombinedStandings {
+	s := CombinedStandings{Competition: competitionID, Totals: []CombinedTotal{}}
+	for bib, events := range a.combinedPoints[competitionID] {
+		t := CombinedTotal{Bib: bib, Events: make(map[string]int, len(events))}
+		for eventID, p := range events {
+			t.Events[eventID] = p
+			t.Points += p
+		}
+		s.Totals = append(s.Totals, t)
+	}
+	sort.Slice(s.Totals, func(i, j int) bool {
+		if s.Totals[i].Points != s.Totals[j].Points {
+			return s.Totals[i].Points > s.Totals[j].Points
+		}
+		return s.Totals[i].Bib < s.Totals[j].Bib
+	})
+	return s
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"PolyField/simulator"
+)
+
+func TestCombinedEventPointsTravelWithResults(t *testing.T) {
+	sp := Event{ID: "hep-sp", Athletes: []Athlete{{Bib: "4", Order: 1}}, Combined: &CombinedEvent{Competition: "hep", Sex: "W", Discipline: "SP"}}
+	srv := newFakePolyFieldServer(t, []Event{sp})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	a.SetServerAddress(host, port)
+
+	hj := Event{ID: "hep-hj", Athletes: []Athlete{{Bib: "4", Order: 1}}, Combined: &CombinedEvent{Competition: "hep", Sex: "W", Discipline: "HJ"}}
+	if _, err := a.StartVerticalJump(hj, "HJ", []float64{1.79, 1.82}); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []float64{1.79, 1.82} {
+		if _, err := a.RecordVerticalAttempt("hep-hj", "4", h, "O"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.FetchEventDetails(host, port, "hep-sp"); err != nil {
+		t.Fatal(err)
+	}
+	series := []Performance{{Attempt: 1, Mark: "FOUL", Unit: "m"}, {Attempt: 2, Mark: "17.07", Unit: "m", Valid: true}}
+	if err := a.PostResult(host, port, ResultPayload{EventID: "hep-sp", AthleteBib: "4", Series: series}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := srv.results()
+	if len(got) != 3 {
+		t.Fatalf("server received %d results", len(got))
+	}
+	if hjCard := got[1]; hjCard.Points != 1003 || hjCard.TotalPoints != 1003 {
+		t.Fatalf("high jump card %+v", hjCard)
+	}
+	if shot := got[2]; shot.Points != 1000 || shot.TotalPoints != 2003 {
+		t.Fatalf("shot result %+v", shot)
+	}
+	standings := a.GetCombinedStandings("hep")
+	if len(standings.Totals) != 1 || standings.Totals[0].Points != 2003 || standings.Totals[0].Events["hep-hj"] != 1003 {
+		t.Fatalf("combined standings %+v", standings)
+	}
+	if err := a.SetCombinedEvent("hep-ht", CombinedEvent{Competition: "hep", Sex: "W", Discipline: "HT"}); err == nil {
+		t.Fatal("hammer accepted as a combined event")
+	}
+}
+
+func TestMeasuredMarkShowsCombinedPoints(t *testing.T) {
+	lj := Event{ID: "hep-lj", Athletes: []Athlete{{Bib: "4", Order: 1}}, Combined: &CombinedEvent{Competition: "hep", Sex: "W", Discipline: "LJ"}}
+	sp := Event{ID: "hep-sp", Athletes: []Athlete{{Bib: "4", Order: 1}}, Combined: &CombinedEvent{Competition: "hep", Sex: "W", Discipline: "SP"}}
+	srv := newFakePolyFieldServer(t, []Event{lj, sp})
+	a, demo := newDemoApp(t)
+	host, port := srv.hostPort()
+	for _, id := range []string{"hep-lj", "hep-sp"} {
+		if _, err := a.FetchEventDetails(host, port, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.PostResult(host, port, ResultPayload{EventID: "hep-lj", AthleteBib: "4", Series: []Performance{{Attempt: 1, Mark: "6.00", Unit: "m", Valid: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveStation(Station{Name: "Shot", EDM: "edm", EventID: "hep-sp"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetStationAthlete("Shot", "4"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := simulator.DefaultEDMConfig()
+	cfg.NoiseMm = 0
+	demo.edms["edm"].SetConfig(cfg)
+	demo.throwDistance = func(string, *rand.Rand) float64 { return 12.34 }
+	if _, err := a.SetCircleCentre("edm"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.MeasureThrow("edm"); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.GetOverlayState(); s.Points != 684 || s.TotalPoints != 684+850 {
+		t.Fatalf("overlay after 12.34 m: points %d, total %d", s.Points, s.TotalPoints)
+	}
+	if err := a.PostResult(host, port, ResultPayload{EventID: "hep-sp", AthleteBib: "4", Series: []Performance{{Attempt: 1, Mark: "13.00", Unit: "m", Valid: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.MeasureThrow("edm"); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.GetOverlayState(); s.Points != 684 || s.TotalPoints != 727+850 {
+		t.Fatalf("overlay after 12.34 m behind a 13.00 m best: points %d, total %d", s.Points, s.TotalPoints)
+	}
+}
@@ -0,0 +1,172 @@
+package competition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"PolyField/api"
+)
+
+// --- Bests & Records ---
+// A valid mark is flagged when it beats a record or the athlete's PB or SB.
+// Equalling one is not enough. A PB is also a season's best but only the PB
+// is shown.
+const (
+	FlagPB = "PB"
+	FlagSB = "SB"
+)
+
+// RecordFlags gives the flag shown for each record kind.
+var RecordFlags = map[string]string{"club": "CLR", "championship": "CR", "meeting": "MR"}
+
+type Bests struct {
+	PB string `json:"pb,omitempty"`
+	SB string `json:"sb,omitempty"`
+}
+
+// EventBests holds what marks in one event are compared with.
+type EventBests struct {
+	Athletes map[string]Bests
+	Records  []api.Record
+}
+
+func NewEventBests() *EventBests { return &EventBests{Athletes: make(map[string]Bests)} }
+
+// Merge takes the athlete bests and records of event, overriding any held.
+func (eb *EventBests) Merge(event api.Event) error {
+	for _, r := range event.Records {
+		if err := eb.SetRecord(r); err != nil {
+			return err
+		}
+	}
+	for _, ath := range event.Athletes {
+		if ath.PB != "" || ath.SB != "" {
+			eb.Athletes[ath.Bib] = Bests{PB: ath.PB, SB: ath.SB}
+		}
+	}
+	return nil
+}
+
+// SetRecord adds a record, replacing one of the same kind.
+func (eb *EventBests) SetRecord(r api.Record) error {
+	if _, ok := RecordFlags[r.Kind]; !ok {
+		return fmt.Errorf("unknown record kind '%s': use club, championship or meeting", r.Kind)
+	}
+	if _, ok := ParseMark(r.Mark); !ok {
+		return fmt.Errorf("%s record: invalid mark '%s'", r.Kind, r.Mark)
+	}
+	for i, held := range eb.Records {
+		if held.Kind == r.Kind {
+			eb.Records[i] = r
+			return nil
+		}
+	}
+	eb.Records = append(eb.Records, r)
+	return nil
+}
+
+// Flags lists what a mark in whole centimetres beats, records first. A mark
+// no better than the athlete's earlier attempts in series sets nothing new.
+func (eb *EventBests) Flags(bib string, cm int, series []api.Performance) []string {
+	if best, ok := BestMark(series); ok && cm <= best {
+		return nil
+	}
+	var flags []string
+	for _, r := range eb.Records {
+		if held, _ := ParseMark(r.Mark); cm > held {
+			flags = append(flags, RecordFlags[r.Kind])
+		}
+	}
+	b := eb.Athletes[bib]
+	switch {
+	case beats(cm, b.PB):
+		flags = append(flags, FlagPB)
+	case beats(cm, b.SB):
+		flags = append(flags, FlagSB)
+	}
+	return flags
+}
+
+// beats is true for a mark better than best. Without a best there is nothing
+// to say.
+func beats(cm int, best string) bool {
+	if best == "" {
+		return false
+	}
+	held, ok := ParseMark(best)
+	return ok && cm > held
+}
+
+// FlagSeries returns a copy of series with each valid attempt flagged
+// against the bests and the attempts before it. posted is the series as last
+// posted: attempts already in it keep the flags they were given, and a new
+// mark that beats a record becomes the record, so later marks have to beat
+// it in turn.
+func (eb *EventBests) FlagSeries(bib string, series, posted []api.Performance) []api.Performance {
+	flagged := make([]api.Performance, len(series))
+	for i, p := range series {
+		p.Flags = nil
+		cm, ok := ParseMark(p.Mark)
+		switch {
+		case !ok || p.Effective() != api.AttemptValid:
+		case i < len(posted) && samePerformance(posted[i], p):
+			p.Flags = posted[i].Flags
+		default:
+			p.Flags = eb.Flags(bib, cm, series[:i])
+			eb.raiseRecords(bib, cm, p.Mark)
+		}
+		flagged[i] = p
+	}
+	return flagged
+}
+func samePerformance(a, b api.Performance) bool {
+	return a.Attempt == b.Attempt && a.Mark == b.Mark && a.Effective() == b.Effective()
+}
+
+// raiseRecords makes a mark the new record of every kind it beats.
+func (eb *EventBests) raiseRecords(holder string, cm int, mark string) {
+	for i, r := range eb.Records {
+		if held, _ := ParseMark(r.Mark); cm > held {
+			eb.Records[i] = api.Record{Kind: r.Kind, Mark: strings.TrimSpace(mark), Holder: holder}
+		}
+	}
+}
+
+// bestsFile is the local file of prior performances, by event.
+type bestsFile struct {
+	Athletes []struct {
+		EventID string `json:"eventId"`
+		Bib     string `json:"bib"`
+		Bests
+	} `json:"athletes"`
+	Records []struct {
+		EventID string `json:"eventId"`
+		api.Record
+	} `json:"records"`
+}
+
+// LoadBests reads a bests file into bests by event ID.
+func LoadBests(r io.Reader) (map[string]*EventBests, error) {
+	var f bestsFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("could not read bests: %w", err)
+	}
+	byEvent := make(map[string]*EventBests)
+	forEvent := func(id string) *EventBests {
+		if byEvent[id] == nil {
+			byEvent[id] = NewEventBests()
+		}
+		return byEvent[id]
+	}
+	for _, rec := range f.Records {
+		if err := forEvent(rec.EventID).SetRecord(rec.Record); err != nil {
+			return nil, fmt.Errorf("event %s: %w", rec.EventID, err)
+		}
+	}
+	for _, ath := range f.Athletes {
+		forEvent(ath.EventID).Athletes[ath.Bib] = ath.Bests
+	}
+	return byEvent, nil
+}
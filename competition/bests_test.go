@@ -0,0 +1,76 @@
+package competition
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"PolyField/api"
+)
+
+func TestFlagSeries(t *testing.T) {
+	eb := NewEventBests()
+	err := eb.Merge(api.Event{
+		Records:  []api.Record{{Kind: "club", Mark: "16.00"}, {Kind: "meeting", Mark: "15.00"}},
+		Athletes: []api.Athlete{{Bib: "1", PB: "15.50", SB: "14.50"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagged := eb.FlagSeries("1", series("14.60", "x", "15.20", "15.10", "15.60", "16.01"), nil)
+	var got [][]string
+	for _, p := range flagged {
+		got = append(got, p.Flags)
+	}
+	want := [][]string{{"SB"}, nil, {"MR", "SB"}, nil, {"MR", "PB"}, {"CLR", "MR", "PB"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("flags %v, want %v", got, want)
+	}
+	if flags := eb.Flags("2", 1700, nil); !reflect.DeepEqual(flags, []string{"CLR", "MR"}) {
+		t.Fatalf("athlete without bests: %v", flags)
+	}
+	if err := eb.SetRecord(api.Record{Kind: "world", Mark: "23.56"}); err == nil {
+		t.Fatal("unknown record kind accepted")
+	}
+}
+
+func TestRecordsRaisedDuringMeeting(t *testing.T) {
+	eb := NewEventBests()
+	eb.SetRecord(api.Record{Kind: "meeting", Mark: "50.00"})
+	a := eb.FlagSeries("A", series("52.00"), nil)
+	if !reflect.DeepEqual(a[0].Flags, []string{"MR"}) {
+		t.Fatalf("A's record %v", a[0].Flags)
+	}
+	// B beats the old record but not A's new one.
+	if b := eb.FlagSeries("B", series("51.00"), nil); b[0].Flags != nil {
+		t.Fatalf("B flagged %v", b[0].Flags)
+	}
+	if eb.Records[0].Mark != "52.00" || eb.Records[0].Holder != "A" {
+		t.Fatalf("record %+v", eb.Records[0])
+	}
+	// Posting A's series again keeps the record on the attempt that set it.
+	a = eb.FlagSeries("A", series("52.00", "x"), a)
+	if !reflect.DeepEqual(a[0].Flags, []string{"MR"}) {
+		t.Fatalf("A's record lost on the next post: %v", a[0].Flags)
+	}
+	if b := eb.FlagSeries("B", series("51.00", "53.10"), nil); !reflect.DeepEqual(b[1].Flags, []string{"MR"}) {
+		t.Fatalf("B's record %v", b[1].Flags)
+	}
+}
+
+func TestLoadBests(t *testing.T) {
+	byEvent, err := LoadBests(strings.NewReader(`{
+		"athletes": [{"eventId": "sp-m", "bib": "7", "pb": "14.00", "sb": "13.20"}],
+		"records": [{"eventId": "sp-m", "kind": "championship", "mark": "17.40", "holder": "A. Thrower"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eb := byEvent["sp-m"]
+	if eb == nil || eb.Athletes["7"].PB != "14.00" || len(eb.Records) != 1 || eb.Records[0].Holder != "A. Thrower" {
+		t.Fatalf("loaded %+v", eb)
+	}
+	if _, err := LoadBests(strings.NewReader(`{"records": [{"eventId": "sp-m", "kind": "club", "mark": "far"}]}`)); err == nil {
+		t.Fatal("record without a mark accepted")
+	}
+}
@@ -0,0 +1,39 @@
+package competition
+
+import "time"
+
+// --- Attempt Time Limits ---
+// An athlete must start the attempt within the limit once called (WA 25.17).
+// The limit grows as the field shrinks in the vertical jumps and when an
+// athlete takes two attempts in a row.
+const (
+	TimeLimitDefault     = time.Minute
+	TimeLimitConsecutive = 2 * time.Minute
+)
+
+// TimeLimit returns the time allowed for an attempt. remaining is the number
+// of athletes still in the competition; consecutive is set when the athlete
+// also took the previous attempt. Combined events are not covered.
+func TimeLimit(discipline string, remaining int, consecutive bool) time.Duration {
+	var fewer, last, inARow time.Duration
+	switch discipline {
+	case "HJ":
+		fewer, last, inARow = 90*time.Second, 3*time.Minute, 2*time.Minute
+	case "PV":
+		fewer, last, inARow = 2*time.Minute, 5*time.Minute, 3*time.Minute
+	default:
+		if consecutive {
+			return TimeLimitConsecutive
+		}
+		return TimeLimitDefault
+	}
+	switch {
+	case remaining == 1:
+		return last
+	case consecutive:
+		return inARow
+	case remaining == 2 || remaining == 3:
+		return fewer
+	}
+	return TimeLimitDefault
+}
@@ -0,0 +1,30 @@
+package competition
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeLimit(t *testing.T) {
+	for _, tc := range []struct {
+		discipline  string
+		remaining   int
+		consecutive bool
+		want        time.Duration
+	}{
+		{"SP", 12, false, time.Minute},
+		{"SP", 1, false, time.Minute},
+		{"LJ", 5, true, 2 * time.Minute},
+		{"HJ", 8, false, time.Minute},
+		{"HJ", 3, false, 90 * time.Second},
+		{"HJ", 3, true, 2 * time.Minute},
+		{"HJ", 1, true, 3 * time.Minute},
+		{"PV", 2, false, 2 * time.Minute},
+		{"PV", 6, true, 3 * time.Minute},
+		{"PV", 1, false, 5 * time.Minute},
+	} {
+		if got := TimeLimit(tc.discipline, tc.remaining, tc.consecutive); got != tc.want {
+			t.Errorf("TimeLimit(%s, %d, %t) = %v, want %v", tc.discipline, tc.remaining, tc.consecutive, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package competition
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"PolyField/api"
+)
+
+// --- Combined Events ---
+// Field events in the decathlon and heptathlon score INT(A * (P - B)^C)
+// points from the World Athletics scoring tables, with P in centimetres for
+// jumps and metres for throws. A mark at or below B scores nothing.
+const (
+	SexMen   = "M"
+	SexWomen = "W"
+)
+
+type pointsTable struct {
+	a, b, c float64
+	jump    bool
+}
+
+// combinedTables holds the field events; the women's tables serve both the
+// heptathlon and the women's decathlon.
+var combinedTables = map[string]map[string]pointsTable{
+	SexMen: {
+		"LJ": {0.14354, 220, 1.4, true},
+		"SP": {51.39, 1.5, 1.05, false},
+		"HJ": {0.8465, 75, 1.42, true},
+		"DT": {12.91, 4, 1.1, false},
+		"PV": {0.2797, 100, 1.35, true},
+		"JT": {10.14, 7, 1.08, false},
+	},
+	SexWomen: {
+		"HJ": {1.84523, 75, 1.348, true},
+		"SP": {56.0211, 1.5, 1.05, false},
+		"LJ": {0.188807, 210, 1.41, true},
+		"JT": {15.9803, 3.8, 1.04, false},
+		"DT": {12.3311, 3, 1.1, false},
+		"PV": {0.44125, 100, 1.35, true},
+	},
+}
+
+// CombinedPoints scores a mark in whole centimetres.
+func CombinedPoints(sex, discipline string, cm int) (int, error) {
+	table, ok := combinedTables[sex][discipline]
+	if !ok {
+		return 0, fmt.Errorf("no combined-events table for '%s' '%s'", sex, discipline)
+	}
+	p := float64(cm)
+	if !table.jump {
+		p /= 100
+	}
+	if p <= table.b {
+		return 0, nil
+	}
+	return int(math.Floor(table.a * math.Pow(p-table.b, table.c))), nil
+}
+
+// BestCleared is the highest bar cleared in a vertical jump card, leaving
+// out any jump-off.
+func BestCleared(records []api.HeightRecord) (cm int, ok bool) {
+	for _, r := range records {
+		if r.JumpOff || !strings.Contains(r.Attempts, Cleared) {
+			continue
+		}
+		if h, valid := ParseMark(r.Height); valid && h > cm {
+			cm, ok = h, true
+		}
+	}
+	return cm, ok
+}
+
+// BestMark is the longest valid mark in a series.
+func BestMark(series []api.Performance) (cm int, ok bool) {
+	for _, p := range series {
+		if p.Effective() != api.AttemptValid {
+			continue
+		}
+		if m, valid := ParseMark(p.Mark); valid && m > cm {
+			cm, ok = m, true
+		}
+	}
+	return cm, ok
+}
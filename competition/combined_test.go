@@ -0,0 +1,48 @@
+package competition
+
+import (
+	"testing"
+
+	"PolyField/api"
+)
+
+func TestCombinedPoints(t *testing.T) {
+	for _, tc := range []struct {
+		sex, discipline string
+		cm, want        int
+	}{
+		{SexMen, "LJ", 776, 1000},
+		{SexMen, "SP", 1840, 1000},
+		{SexMen, "HJ", 220, 992},
+		{SexMen, "DT", 5617, 1000},
+		{SexMen, "PV", 529, 1001},
+		{SexMen, "JT", 7719, 1000},
+		{SexWomen, "HJ", 182, 1003},
+		{SexWomen, "SP", 1707, 1000},
+		{SexWomen, "JT", 5718, 1000},
+		{SexWomen, "PV", 400, 974},
+		{SexMen, "SP", 150, 0},
+		{SexMen, "LJ", 100, 0},
+	} {
+		got, err := CombinedPoints(tc.sex, tc.discipline, tc.cm)
+		if err != nil || got != tc.want {
+			t.Errorf("CombinedPoints(%s, %s, %d) = %d, %v; want %d", tc.sex, tc.discipline, tc.cm, got, err, tc.want)
+		}
+	}
+	if _, err := CombinedPoints(SexWomen, "HT", 5000); err == nil {
+		t.Error("hammer has no combined-events table")
+	}
+}
+
+func TestBestMarks(t *testing.T) {
+	if cm, ok := BestMark(series("x", "14.05", "14.50")); !ok || cm != 1450 {
+		t.Errorf("BestMark = %d, %v", cm, ok)
+	}
+	if _, ok := BestMark(series("x", "x")); ok {
+		t.Error("BestMark found a mark in a series of fouls")
+	}
+	records := []api.HeightRecord{{Height: "1.80", Attempts: "O"}, {Height: "1.85", Attempts: "XO"}, {Height: "1.88", Attempts: "XXX"}, {Height: "1.90", Attempts: "O", JumpOff: true}}
+	if cm, ok := BestCleared(records); !ok || cm != 185 {
+		t.Errorf("BestCleared = %d, %v", cm, ok)
+	}
+}
@@ -0,0 +1,145 @@
+package competition
+
+import (
+	"fmt"
+	"sort"
+
+	"PolyField/api"
+)
+
+// --- Flights ---
+// Large fields compete in flights that take their preliminary rounds one
+// after another. Everyone is then ranked together and the best go through
+// to a combined final (WA 25.6).
+const (
+	FinalFlight       = "Final"
+	DefaultQualifiers = 8
+)
+
+// SplitFlights divides athletes, in start order, into flights of at most
+// size, as evenly as possible, named A, B, C...
+func SplitFlights(bibs []string, size int) ([]api.Flight, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("flight size must be at least 1")
+	}
+	n := (len(bibs) + size - 1) / size
+	if n > 26 {
+		return nil, fmt.Errorf("%d flights of %d is too many", n, size)
+	}
+	flights := make([]api.Flight, 0, n)
+	for i, start := 0, 0; i < n; i++ {
+		// Spread the remainder over the first flights.
+		count := len(bibs) / n
+		if i < len(bibs)%n {
+			count++
+		}
+		flights = append(flights, api.Flight{Name: string(rune('A' + i)), Bibs: append([]string(nil), bibs[start:start+count]...)})
+		start += count
+	}
+	return flights, nil
+}
+
+// ValidateFlights checks that every athlete is in exactly one flight and that
+// flight names are distinct. A final flight only has to hold entered athletes.
+func ValidateFlights(flights []api.Flight, bibs []string) error {
+	entered := make(map[string]bool, len(bibs))
+	for _, bib := range bibs {
+		entered[bib] = true
+	}
+	names := make(map[string]bool, len(flights))
+	placed := make(map[string]string, len(bibs))
+	for _, f := range flights {
+		if f.Name == "" || names[f.Name] {
+			return fmt.Errorf("flight names must be unique and not empty ('%s')", f.Name)
+		}
+		names[f.Name] = true
+		for _, bib := range f.Bibs {
+			if !entered[bib] {
+				return fmt.Errorf("bib %s in flight %s is not entered in the event", bib, f.Name)
+			}
+			if f.Name == FinalFlight {
+				continue
+			}
+			if other, ok := placed[bib]; ok {
+				return fmt.Errorf("bib %s is in flights %s and %s", bib, other, f.Name)
+			}
+			placed[bib] = f.Name
+		}
+	}
+	for _, bib := range bibs {
+		if _, ok := placed[bib]; !ok {
+			return fmt.Errorf("bib %s is not in any flight", bib)
+		}
+	}
+	return nil
+}
+
+// NextInRotation returns who throws next in order: the first athlete with
+// the fewest attempts, skipping those who are out. done is true once every
+// athlete still competing has had rounds attempts.
+func NextInRotation(order []string, attempts map[string]int, out map[string]bool, rounds int) (bib string, attempt int, done bool) {
+	fewest := rounds
+	for _, b := range order {
+		if !out[b] && attempts[b] < fewest {
+			bib, fewest = b, attempts[b]
+		}
+	}
+	if bib == "" {
+		return "", 0, true
+	}
+	return bib, fewest + 1, false
+}
+
+// Cut returns the qualifiers for the final: the top n ranked athletes plus
+// anyone tied with the last of them (WA 25.6.1). Retired athletes take no
+// further trials and leave their place to the next.
+func Cut(standings []Standing, n int) []string {
+	var qualifiers []string
+	lastRank := 0
+	for _, s := range standings {
+		if s.Rank == 0 || s.Status == StatusRetired {
+			continue
+		}
+		if len(qualifiers) >= n && s.Rank != lastRank {
+			break
+		}
+		qualifiers = append(qualifiers, s.Bib)
+		lastRank = s.Rank
+	}
+	return qualifiers
+}
+
+// MergeFinal ranks all flights together and builds the final flight. With
+// reorder the qualifiers throw in reverse order of ranking; athletes sharing
+// a place keep their start order. Otherwise start order is kept throughout.
+func MergeFinal(flights []api.Flight, entries []Entry, qualifiers int, reorder bool) api.Flight {
+	if qualifiers < 1 {
+		qualifiers = DefaultQualifiers
+	}
+	startPos := make(map[string]int)
+	for _, f := range flights {
+		for _, bib := range f.Bibs {
+			startPos[bib] = len(startPos)
+		}
+	}
+	var inFlights []Entry
+	for _, e := range entries {
+		if _, ok := startPos[e.Bib]; ok {
+			inFlights = append(inFlights, e)
+		}
+	}
+	standings := RankHorizontal(inFlights)
+	rank := make(map[string]int, len(standings))
+	for _, s := range standings {
+		rank[s.Bib] = s.Rank
+	}
+	final := Cut(standings, qualifiers)
+	sort.SliceStable(final, func(i, j int) bool {
+		a, b := final[i], final[j]
+		if reorder && rank[a] != rank[b] {
+			return rank[a] > rank[b]
+		}
+		return startPos[a] < startPos[b]
+	})
+	return api.Flight{Name: FinalFlight, Bibs: final}
+}
@@ -0,0 +1,65 @@
+package competition
+
+import (
+	"reflect"
+	"testing"
+
+	"PolyField/api"
+)
+
+func TestSplitFlights(t *testing.T) {
+	flights, err := SplitFlights([]string{"1", "2", "3", "4", "5", "6", "7"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []api.Flight{{Name: "A", Bibs: []string{"1", "2", "3"}}, {Name: "B", Bibs: []string{"4", "5"}}, {Name: "C", Bibs: []string{"6", "7"}}}
+	if !reflect.DeepEqual(flights, want) {
+		t.Fatalf("flights %+v, want %+v", flights, want)
+	}
+	bibs := []string{"1", "2", "3"}
+	for _, bad := range [][]api.Flight{
+		{{Name: "A", Bibs: []string{"1", "2"}}},
+		{{Name: "A", Bibs: []string{"1", "2"}}, {Name: "B", Bibs: []string{"2", "3"}}},
+		{{Name: "A", Bibs: []string{"1"}}, {Name: "A", Bibs: []string{"2", "3"}}},
+		{{Name: "A", Bibs: []string{"1", "2", "3", "9"}}},
+	} {
+		if err := ValidateFlights(bad, bibs); err == nil {
+			t.Errorf("ValidateFlights accepted %+v", bad)
+		}
+	}
+}
+
+func TestNextInRotation(t *testing.T) {
+	order := []string{"1", "2", "3"}
+	attempts := map[string]int{"1": 1, "2": 1}
+	if bib, n, done := NextInRotation(order, attempts, nil, 3); bib != "3" || n != 1 || done {
+		t.Fatalf("next = %s #%d done=%v, want 3 #1", bib, n, done)
+	}
+	attempts["3"] = 1
+	if bib, n, _ := NextInRotation(order, attempts, map[string]bool{"1": true}, 3); bib != "2" || n != 2 {
+		t.Fatalf("next = %s #%d, want 2 #2 with 1 retired", bib, n)
+	}
+	if _, _, done := NextInRotation(order, map[string]int{"1": 3, "2": 3, "3": 3}, nil, 3); !done {
+		t.Fatal("rotation not done after three rounds")
+	}
+}
+
+func TestMergeFinalReordersQualifiers(t *testing.T) {
+	flights := []api.Flight{{Name: "A", Bibs: []string{"a1", "a2", "a3"}}, {Name: "B", Bibs: []string{"b1", "b2", "b3"}}}
+	entries := []Entry{
+		{Bib: "a1", Series: series("50.00")},
+		{Bib: "a2", Series: series("40.00")},
+		{Bib: "a3", Series: series("x")},
+		{Bib: "b1", Series: series("45.00")},
+		{Bib: "b2", Series: series("40.00")},
+		{Bib: "b3", Series: series("60.00"), Status: StatusRetired},
+	}
+	final := MergeFinal(flights, entries, 3, true)
+	// b3 retired; a2 and b2 tie for third and both go through in start order.
+	if want := []string{"a2", "b2", "b1", "a1"}; final.Name != FinalFlight || !reflect.DeepEqual(final.Bibs, want) {
+		t.Fatalf("final %+v, want %v", final, want)
+	}
+	if got := MergeFinal(flights, entries, 3, false).Bibs; !reflect.DeepEqual(got, []string{"a1", "a2", "b1", "b2"}) {
+		t.Fatalf("final without reorder %v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package competition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"PolyField/api"
+)
+
+// --- Implements & Age Groups ---
+// Throwing events name their implement; its weight must be one in use in
+// some age group, and the calibrated circle must be the one it is thrown from.
+var implementWeights = map[string][]float64{
+	"SHOT":    {7.26, 6, 5, 4, 3, 2},
+	"DISCUS":  {2, 1.75, 1.5, 1, 0.75},
+	"HAMMER":  {7.26, 6, 5, 4, 3, 2},
+	"JAVELIN": {0.8, 0.7, 0.6, 0.5, 0.4},
+}
+var implementCircles = map[string]string{"SHOT": "SHOT", "DISCUS": "DISCUS", "HAMMER": "HAMMER", "JAVELIN": "JAVELIN_ARC"}
+
+// ImplementDisciplines gives the discipline code each implement is thrown in.
+var ImplementDisciplines = map[string]string{"SHOT": "SP", "DISCUS": "DT", "HAMMER": "HT", "JAVELIN": "JT"}
+
+// MastersMinAge is the youngest age of the WMA masters groups.
+const MastersMinAge = 35
+
+func ValidateImplement(imp api.Implement) error {
+	weights, ok := implementWeights[imp.Type]
+	if !ok {
+		return fmt.Errorf("unknown implement '%s': use SHOT, DISCUS, HAMMER or JAVELIN", imp.Type)
+	}
+	for _, w := range weights {
+		if math.Abs(w-imp.Weight) < 0.001 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s of %gkg is not a standard weight", strings.ToLower(imp.Type), imp.Weight)
+}
+
+// ImplementCircle is the circle type (as used for calibration) the implement
+// is thrown from.
+func ImplementCircle(implementType string) (string, error) {
+	circle, ok := implementCircles[implementType]
+	if !ok {
+		return "", fmt.Errorf("unknown implement '%s'", implementType)
+	}
+	return circle, nil
+}
+
+// ParseMastersGroup reads a masters age group such as "M50" or "W35". ok is
+// false for any other group (U17, Senior...).
+func ParseMastersGroup(group string) (sex string, age int, ok bool) {
+	sex, age, ok = splitAgeGroup(group)
+	if !ok || age < MastersMinAge {
+		return "", 0, false
+	}
+	return sex, age, true
+}
+func splitAgeGroup(group string) (sex string, age int, ok bool) {
+	if len(group) < 2 || (group[:1] != SexMen && group[:1] != SexWomen) {
+		return "", 0, false
+	}
+	age, err := strconv.Atoi(group[1:])
+	return group[:1], age, err == nil
+}
+
+// ValidateEvent checks the event's implement and that an age group written
+// like a masters group is one.
+func ValidateEvent(event api.Event) error {
+	if event.Implement != nil {
+		if err := ValidateImplement(*event.Implement); err != nil {
+			return err
+		}
+	}
+	if _, age, ok := splitAgeGroup(event.AgeGroup); ok && age < MastersMinAge {
+		return fmt.Errorf("age group '%s' is below the masters ages", event.AgeGroup)
+	}
+	return nil
+}
+
+// --- Age Grading ---
+// WMA age grading compares a mark with the age standard: the open standard
+// times the factor for the athlete's sex, discipline and age. The factors
+// are published by WMA and loaded from a file; none ship with PolyField.
+type AgeFactorTable struct {
+	Sex          string `json:"sex"`
+	Discipline   string `json:"discipline"`
+	OpenStandard string `json:"openStandard"`
+	// Factors by age in whole years.
+	Factors map[int]float64 `json:"factors"`
+}
+type AgeFactors struct {
+	Tables []AgeFactorTable `json:"tables"`
+}
+
+// LoadAgeFactors reads a JSON factor file and checks every table.
+func LoadAgeFactors(r io.Reader) (*AgeFactors, error) {
+	var f AgeFactors
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("could not read age factors: %w", err)
+	}
+	for _, t := range f.Tables {
+		if cm, ok := ParseMark(t.OpenStandard); !ok || cm == 0 {
+			return nil, fmt.Errorf("age factors for %s %s: bad open standard '%s'", t.Sex, t.Discipline, t.OpenStandard)
+		}
+		for age, factor := range t.Factors {
+			if factor <= 0 || factor > 1 {
+				return nil, fmt.Errorf("age factors for %s %s: factor %g at age %d is out of range", t.Sex, t.Discipline, factor, age)
+			}
+		}
+	}
+	return &f, nil
+}
+
+// Grade returns the age-graded percentage of a mark in whole centimetres,
+// to two decimal places.
+func (f *AgeFactors) Grade(sex, discipline string, age, cm int) (float64, error) {
+	for _, t := range f.Tables {
+		if t.Sex != sex || t.Discipline != discipline {
+			continue
+		}
+		factor, ok := t.Factors[age]
+		if !ok {
+			return 0, fmt.Errorf("no %s %s age factor for age %d", sex, discipline, age)
+		}
+		open, _ := ParseMark(t.OpenStandard)
+		return math.Round(float64(cm)/(float64(open)*factor)*10000) / 100, nil
+	}
+	return 0, fmt.Errorf("no age factors for %s %s", sex, discipline)
+}
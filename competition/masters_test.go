@@ -0,0 +1,57 @@
+package competition
+
+import (
+	"strings"
+	"testing"
+
+	"PolyField/api"
+)
+
+func TestValidateEvent(t *testing.T) {
+	for _, tc := range []struct {
+		event api.Event
+		ok    bool
+	}{
+		{api.Event{AgeGroup: "M60", Implement: &api.Implement{Type: "SHOT", Weight: 5}}, true},
+		{api.Event{AgeGroup: "Senior", Implement: &api.Implement{Type: "JAVELIN", Weight: 0.8}}, true},
+		{api.Event{AgeGroup: "U17"}, true},
+		{api.Event{Implement: &api.Implement{Type: "SHOT", Weight: 5.5}}, false},
+		{api.Event{Implement: &api.Implement{Type: "WEIGHT", Weight: 15.88}}, false},
+		{api.Event{AgeGroup: "W30"}, false},
+	} {
+		if err := ValidateEvent(tc.event); (err == nil) != tc.ok {
+			t.Errorf("ValidateEvent(%+v) = %v", tc.event, err)
+		}
+	}
+	if circle, _ := ImplementCircle("JAVELIN"); circle != "JAVELIN_ARC" {
+		t.Errorf("javelin circle %q", circle)
+	}
+	if sex, age, ok := ParseMastersGroup("W55"); !ok || sex != SexWomen || age != 55 {
+		t.Errorf("ParseMastersGroup(W55) = %s, %d, %v", sex, age, ok)
+	}
+}
+
+// The factors below are made up for the test, not WMA values.
+const testAgeFactors = `{"tables": [
+	{"sex": "M", "discipline": "SP", "openStandard": "20.00", "factors": {"60": 0.8, "61": 0.78}}
+]}`
+
+func TestAgeGrading(t *testing.T) {
+	f, err := LoadAgeFactors(strings.NewReader(testAgeFactors))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 12.00 against an age standard of 16.00.
+	if got, err := f.Grade(SexMen, "SP", 60, 1200); err != nil || got != 75 {
+		t.Fatalf("Grade = %v, %v", got, err)
+	}
+	if _, err := f.Grade(SexMen, "SP", 62, 1200); err == nil {
+		t.Fatal("graded an age without a factor")
+	}
+	if _, err := f.Grade(SexWomen, "SP", 60, 1200); err == nil {
+		t.Fatal("graded without a table")
+	}
+	if _, err := LoadAgeFactors(strings.NewReader(`{"tables": [{"sex": "M", "discipline": "SP", "openStandard": "20.00", "factors": {"60": 1.8}}]}`)); err == nil {
+		t.Fatal("factor above 1 accepted")
+	}
+}
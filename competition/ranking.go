@@ -0,0 +1,190 @@
+package competition
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"PolyField/api"
+)
+
+// --- Horizontal Event Ranking ---
+// Throws and horizontal jumps rank on the best valid mark, then the second
+// best and so on (WA 25.22). Athletes still level share the place.
+const (
+	StatusNM      = "NM"
+	StatusDNS     = "DNS"
+	StatusDNF     = "DNF"
+	StatusRetired = "r"
+	StatusDQ      = "DQ"
+
+	// DefaultAttempts is the number of attempts when the event does not say.
+	DefaultAttempts = 3
+)
+
+// Entry is one athlete's series. Status is what the judge declared (DNS, DNF
+// or r); NM is worked out from the series. Attempts is how many the athlete
+// is allowed, DefaultAttempts when 0.
+type Entry struct {
+	Bib      string
+	Series   []api.Performance
+	Status   string
+	Attempts int
+}
+
+// AthleteStatus combines the declared status with the attempt statuses. A
+// disqualification outweighs everything; otherwise a declaration stands.
+func (e Entry) AthleteStatus() string {
+	fromSeries := ""
+	for _, p := range e.Series {
+		switch p.Effective() {
+		case api.AttemptDQ:
+			return StatusDQ
+		case api.AttemptRetired:
+			fromSeries = StatusRetired
+		case api.AttemptDNS:
+			fromSeries = StatusDNS
+		}
+	}
+	if e.Status != "" {
+		return e.Status
+	}
+	return fromSeries
+}
+
+// Taken counts the attempts made, leaving out placeholders for rounds still
+// to come.
+func (e Entry) Taken() int {
+	n := 0
+	for _, p := range e.Series {
+		if p.Taken() {
+			n++
+		}
+	}
+	return n
+}
+
+// Complete reports whether the athlete has taken all their attempts.
+func (e Entry) Complete() bool {
+	allowed := e.Attempts
+	if allowed < 1 {
+		allowed = DefaultAttempts
+	}
+	return e.Taken() >= allowed
+}
+
+// Out reports whether the athlete takes no further attempts.
+func (e Entry) Out() bool {
+	switch e.AthleteStatus() {
+	case StatusDNS, StatusDNF, StatusRetired, StatusDQ:
+		return true
+	}
+	return false
+}
+
+// Standing is an athlete's live place. Athletes without a valid mark have no
+// rank and carry a status instead, or none while they have yet to compete.
+type Standing struct {
+	Rank   int      `json:"rank,omitempty"`
+	Bib    string   `json:"bib"`
+	Best   string   `json:"best,omitempty"`
+	Marks  []string `json:"marks,omitempty"`
+	Status string   `json:"status,omitempty"`
+}
+
+// ParseMark reads a distance mark such as "12.34" or "12.34 m" as whole
+// centimetres.
+func ParseMark(mark string) (cm int, ok bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(mark), "m")), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+		return 0, false
+	}
+	return int(math.Round(v * 100)), true
+}
+
+type rankedEntry struct {
+	standing Standing
+	marks    []int
+}
+
+// unrankedOrder lists athletes without a mark: those yet to compete, then
+// NM, DNF, DNS and DQ.
+var unrankedOrder = map[string]int{"": 0, StatusNM: 1, StatusRetired: 1, StatusDNF: 2, StatusDNS: 3, StatusDQ: 4}
+
+// RankHorizontal orders entries for the results list; unranked athletes keep
+// the order they were given in, normally the start order. A disqualified
+// athlete's marks do not count.
+func RankHorizontal(entries []Entry) []Standing {
+	ranked := make([]rankedEntry, 0, len(entries))
+	for _, e := range entries {
+		r := rankedEntry{standing: Standing{Bib: e.Bib}}
+		status := e.AthleteStatus()
+		type mark struct {
+			cm   int
+			text string
+		}
+		var valid []mark
+		for _, p := range e.Series {
+			if p.Effective() != api.AttemptValid || status == StatusDQ {
+				continue
+			}
+			if cm, ok := ParseMark(p.Mark); ok {
+				valid = append(valid, mark{cm, strings.TrimSpace(p.Mark)})
+			}
+		}
+		sort.SliceStable(valid, func(i, j int) bool { return valid[i].cm > valid[j].cm })
+		for _, m := range valid {
+			r.marks = append(r.marks, m.cm)
+			r.standing.Marks = append(r.standing.Marks, m.text)
+		}
+		switch {
+		case len(valid) > 0:
+			r.standing.Best = valid[0].text
+			if status == StatusRetired {
+				r.standing.Status = StatusRetired
+			}
+		case status == StatusDNS || status == StatusDNF || status == StatusDQ:
+			r.standing.Status = status
+		case e.Complete() || status == StatusRetired:
+			r.standing.Status = StatusNM
+		}
+		ranked = append(ranked, r)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if (len(a.marks) > 0) != (len(b.marks) > 0) {
+			return len(a.marks) > 0
+		}
+		if len(a.marks) == 0 {
+			return unrankedOrder[a.standing.Status] < unrankedOrder[b.standing.Status]
+		}
+		return compareMarks(a.marks, b.marks) > 0
+	})
+	standings := make([]Standing, len(ranked))
+	for i, r := range ranked {
+		standings[i] = r.standing
+		if len(r.marks) == 0 {
+			continue
+		}
+		standings[i].Rank = i + 1
+		if i > 0 && compareMarks(ranked[i-1].marks, r.marks) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings
+}
+
+// compareMarks compares two series sorted best first: the first difference
+// decides, and a further valid mark beats none.
+func compareMarks(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] > b[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,96 @@
+package competition
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"PolyField/api"
+)
+
+// series builds performances from marks; "x" is a foul.
+func series(marks ...string) []api.Performance {
+	var s []api.Performance
+	for i, m := range marks {
+		p := api.Performance{Attempt: i + 1, Mark: m, Unit: "m", Valid: true}
+		if m == "x" {
+			p = api.Performance{Attempt: i + 1, Mark: "FOUL", Unit: "m"}
+		}
+		s = append(s, p)
+	}
+	return s
+}
+
+func TestRankHorizontalTieBreaks(t *testing.T) {
+	standings := RankHorizontal([]Entry{
+		{Bib: "DNS", Status: StatusDNS},
+		{Bib: "A", Series: series("15.20", "x", "14.80")},
+		{Bib: "NM", Series: series("x", "x", "x")},
+		{Bib: "TO-COME", Series: append(series("x"), api.Performance{Attempt: 2, Unit: "m", Valid: true}, api.Performance{Attempt: 3, Unit: "m", Valid: true})},
+		{Bib: "FINAL", Series: series("x", "x", "x"), Attempts: 6},
+		{Bib: "B", Series: series("14.90", "15.20 m", "x")},
+		{Bib: "C", Series: series("15.20", "14.80", "x")},
+		{Bib: "D", Series: series("15.20")},
+		{Bib: "WAIT"},
+		{Bib: "R", Series: series("13.00"), Status: StatusRetired},
+		{Bib: "RNM", Series: series("x"), Status: StatusRetired},
+	})
+	var got []string
+	for _, s := range standings {
+		got = append(got, strings.Join([]string{s.Bib, strconv.Itoa(s.Rank), s.Best, s.Status}, "/"))
+	}
+	want := []string{
+		"B/1/15.20 m/", // second best 14.90 beats 14.80
+		"A/2/15.20/",
+		"C/2/15.20/", // same two marks as A: shared place
+		"D/4/15.20/", // no second mark
+		"R/5/13.00/r",
+		"TO-COME/0//", // attempts still to take
+		"FINAL/0//",
+		"WAIT/0//",
+		"NM/0//NM",
+		"RNM/0//NM",
+		"DNS/0//DNS",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("standings\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestParseMark(t *testing.T) {
+	for mark, want := range map[string]int{"12.34": 1234, "12.34 m": 1234, " 8.005m": 801, "60": 6000} {
+		if cm, ok := ParseMark(mark); !ok || cm != want {
+			t.Errorf("ParseMark(%q) = %d, %v; want %d", mark, cm, ok, want)
+		}
+	}
+	for _, mark := range []string{"", "FOUL", "-1", "NaN", "Inf"} {
+		if _, ok := ParseMark(mark); ok {
+			t.Errorf("ParseMark(%q) accepted", mark)
+		}
+	}
+}
+
+func TestRankHorizontalAttemptStatuses(t *testing.T) {
+	retired := append(series("16.00"), api.Performance{Attempt: 2, Status: api.AttemptRetired})
+	dq := append(series("17.00"), api.Performance{Attempt: 2, Status: api.AttemptDQ, Rule: "7.1", Card: api.CardRed})
+	passed := []api.Performance{{Attempt: 1, Status: api.AttemptPass}, {Attempt: 2, Mark: "15.00", Status: api.AttemptValid, Valid: true}}
+	standings := RankHorizontal([]Entry{
+		{Bib: "DQ", Series: dq},
+		{Bib: "R", Series: retired},
+		{Bib: "P", Series: passed},
+		{Bib: "DNS", Series: []api.Performance{{Attempt: 1, Status: api.AttemptDNS}}},
+		{Bib: "T", Series: []api.Performance{{Attempt: 1, Mark: "14.00", Status: api.AttemptTimeLimit}}, Attempts: 1},
+	})
+	var got []string
+	for _, s := range standings {
+		got = append(got, strings.Join([]string{s.Bib, strconv.Itoa(s.Rank), s.Best, s.Status}, "/"))
+	}
+	want := []string{"R/1/16.00/r", "P/2/15.00/", "T/0//NM", "DNS/0//DNS", "DQ/0//DQ"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("standings\n got %v\nwant %v", got, want)
+	}
+	if cut := Cut(standings, 8); !reflect.DeepEqual(cut, []string{"P"}) {
+		t.Fatalf("cut %v", cut)
+	}
+}
@@ -0,0 +1,457 @@
+// Package competition holds the rules of field events, independent of
+// devices and the UI.
+package competition
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"PolyField/api"
+)
+
+// --- Vertical Jumps ---
+// Heights are whole centimetres throughout so bar positions compare exactly.
+const (
+	Cleared = "O"
+	Failed  = "X"
+	Passed  = "-"
+
+	maxVerticalFailures = 3
+)
+
+// Centimetres rounds a height in metres to the bar position it means.
+func Centimetres(metres float64) int { return int(math.Round(metres * 100)) }
+
+// FormatHeight writes a height the way marks are sent to the server.
+func FormatHeight(cm int) string { return fmt.Sprintf("%d.%02d", cm/100, cm%100) }
+
+// JumpOffStep is how far the bar moves between jump-off heights (WA 26.9.4).
+func JumpOffStep(discipline string) (int, error) {
+	switch discipline {
+	case "HJ":
+		return 2, nil
+	case "PV":
+		return 5, nil
+	}
+	return 0, fmt.Errorf("unknown vertical jump '%s': use HJ or PV", discipline)
+}
+
+// VerticalJump is one high jump or pole vault competition. It is not safe for
+// concurrent use.
+type VerticalJump struct {
+	Discipline string
+	heights    []int
+	athletes   []*verticalAthlete
+	jumpOff    *jumpOff
+}
+type verticalAthlete struct {
+	bib      string
+	attempts map[int]string
+	retired  bool
+}
+
+// VerticalStanding is an athlete's place. Best is 0 until a height is
+// cleared; such athletes have no rank. JumpOff marks athletes tied for first
+// who have finished competing, so a jump-off can decide the winner.
+type VerticalStanding struct {
+	Rank           int    `json:"rank"`
+	Bib            string `json:"bib"`
+	Best           int    `json:"best"`
+	FailuresAtBest int    `json:"failuresAtBest"`
+	TotalFailures  int    `json:"totalFailures"`
+	Eliminated     bool   `json:"eliminated"`
+	Retired        bool   `json:"retired"`
+	JumpOff        bool   `json:"jumpOff,omitempty"`
+}
+
+func NewVerticalJump(discipline string, heights []int, bibs []string) (*VerticalJump, error) {
+	if _, err := JumpOffStep(discipline); err != nil {
+		return nil, err
+	}
+	v := &VerticalJump{Discipline: discipline}
+	for _, h := range heights {
+		if err := v.AddHeight(h); err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[string]bool, len(bibs))
+	for _, bib := range bibs {
+		if seen[bib] {
+			return nil, fmt.Errorf("bib %s is listed twice", bib)
+		}
+		seen[bib] = true
+		v.athletes = append(v.athletes, &verticalAthlete{bib: bib, attempts: make(map[int]string)})
+	}
+	return v, nil
+}
+
+// AddHeight extends the progression; each height must be above the last.
+func (v *VerticalJump) AddHeight(cm int) error {
+	if cm <= 0 {
+		return fmt.Errorf("invalid bar height %s", FormatHeight(cm))
+	}
+	if n := len(v.heights); n > 0 && cm <= v.heights[n-1] {
+		return fmt.Errorf("bar height %s is not above %s", FormatHeight(cm), FormatHeight(v.heights[n-1]))
+	}
+	v.heights = append(v.heights, cm)
+	return nil
+}
+func (v *VerticalJump) Heights() []int { return append([]int(nil), v.heights...) }
+
+func (v *VerticalJump) athlete(bib string) (*verticalAthlete, error) {
+	for _, ath := range v.athletes {
+		if ath.bib == bib {
+			return ath, nil
+		}
+	}
+	return nil, fmt.Errorf("bib %s is not in this competition", bib)
+}
+
+// Record adds one attempt, or a pass of the rest of the height, for bib.
+func (v *VerticalJump) Record(bib string, cm int, result string) error {
+	if result != Cleared && result != Failed && result != Passed {
+		return fmt.Errorf("invalid attempt '%s': use O, X or -", result)
+	}
+	if v.jumpOff != nil {
+		return fmt.Errorf("the jump-off has started")
+	}
+	ath, err := v.athlete(bib)
+	if err != nil {
+		return err
+	}
+	if ath.retired || ath.eliminated() {
+		return fmt.Errorf("bib %s is out of the competition", bib)
+	}
+	if !v.hasHeight(cm) {
+		return fmt.Errorf("%s is not in the bar progression", FormatHeight(cm))
+	}
+	if top := ath.highestAttempted(); top > cm {
+		return fmt.Errorf("bib %s has already moved on to %s", bib, FormatHeight(top))
+	}
+	switch at := ath.attempts[cm]; {
+	case strings.Contains(at, Cleared):
+		return fmt.Errorf("bib %s has already cleared %s", bib, FormatHeight(cm))
+	case strings.Contains(at, Passed):
+		return fmt.Errorf("bib %s passed %s", bib, FormatHeight(cm))
+	}
+	ath.attempts[cm] += result
+	return nil
+}
+
+// Retire ends bib's competition at their own request; heights already
+// cleared still count.
+func (v *VerticalJump) Retire(bib string) error {
+	ath, err := v.athlete(bib)
+	if err != nil {
+		return err
+	}
+	ath.retired = true
+	return nil
+}
+
+// Retired lists the athletes who have retired, in start order.
+func (v *VerticalJump) Retired() []string {
+	var bibs []string
+	for _, ath := range v.athletes {
+		if ath.retired {
+			bibs = append(bibs, ath.bib)
+		}
+	}
+	return bibs
+}
+func (v *VerticalJump) hasHeight(cm int) bool {
+	for _, h := range v.heights {
+		if h == cm {
+			return true
+		}
+	}
+	return false
+}
+
+// Records is bib's card, lowest height first, including any jump-off.
+func (v *VerticalJump) Records(bib string) ([]api.HeightRecord, error) {
+	ath, err := v.athlete(bib)
+	if err != nil {
+		return nil, err
+	}
+	var records []api.HeightRecord
+	for _, h := range ath.sortedHeights() {
+		records = append(records, api.HeightRecord{Height: FormatHeight(h), Attempts: ath.attempts[h]})
+	}
+	if v.jumpOff != nil {
+		for _, round := range v.jumpOff.rounds {
+			if res, ok := round.results[bib]; ok {
+				records = append(records, api.HeightRecord{Height: FormatHeight(round.height), Attempts: res, JumpOff: true})
+			}
+		}
+	}
+	return records, nil
+}
+func (ath *verticalAthlete) sortedHeights() []int {
+	heights := make([]int, 0, len(ath.attempts))
+	for h := range ath.attempts {
+		heights = append(heights, h)
+	}
+	sort.Ints(heights)
+	return heights
+}
+func (ath *verticalAthlete) highestAttempted() int {
+	top := 0
+	for h := range ath.attempts {
+		top = max(top, h)
+	}
+	return top
+}
+
+// eliminated is three consecutive failures, whatever the heights; passes
+// do not break the run.
+func (ath *verticalAthlete) eliminated() bool {
+	run := 0
+	for _, h := range ath.sortedHeights() {
+		for _, c := range ath.attempts[h] {
+			switch string(c) {
+			case Failed:
+				run++
+			case Cleared:
+				run = 0
+			}
+		}
+	}
+	return run >= maxVerticalFailures
+}
+
+// standing computes the countback figures (WA 26.8): failures at the best
+// height, then failures at all heights up to and including it.
+func (ath *verticalAthlete) standing() VerticalStanding {
+	s := VerticalStanding{Bib: ath.bib, Eliminated: ath.eliminated(), Retired: ath.retired}
+	failures := 0
+	for _, h := range ath.sortedHeights() {
+		at := ath.attempts[h]
+		failures += strings.Count(at, Failed)
+		if strings.Contains(at, Cleared) {
+			s.Best = h
+			s.FailuresAtBest = strings.Count(at, Failed)
+			s.TotalFailures = failures
+		}
+	}
+	return s
+}
+func (s VerticalStanding) finished() bool { return s.Eliminated || s.Retired }
+func sameCountback(a, b VerticalStanding) bool {
+	return a.Best == b.Best && a.FailuresAtBest == b.FailuresAtBest && a.TotalFailures == b.TotalFailures
+}
+
+// Standings orders athletes by best height and countback. Athletes still
+// tied share a place, except that a completed jump-off decides first.
+func (v *VerticalJump) Standings() []VerticalStanding {
+	standings := make([]VerticalStanding, 0, len(v.athletes))
+	for _, ath := range v.athletes {
+		standings = append(standings, ath.standing())
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Best != b.Best {
+			return a.Best > b.Best
+		}
+		if a.FailuresAtBest != b.FailuresAtBest {
+			return a.FailuresAtBest < b.FailuresAtBest
+		}
+		if a.TotalFailures != b.TotalFailures {
+			return a.TotalFailures < b.TotalFailures
+		}
+		return v.jumpOffPlace(a.Bib) < v.jumpOffPlace(b.Bib)
+	})
+	tiedFirst := v.tiedForFirst(standings)
+	for i := range standings {
+		s := &standings[i]
+		if s.Best == 0 {
+			continue
+		}
+		s.Rank = i + 1
+		if i > 0 && sameCountback(standings[i-1], *s) && v.jumpOffPlace(standings[i-1].Bib) == v.jumpOffPlace(s.Bib) {
+			s.Rank = standings[i-1].Rank
+		}
+		if v.jumpOff == nil && len(tiedFirst) > 1 && s.Rank == 1 {
+			s.JumpOff = true
+		}
+	}
+	return standings
+}
+
+// Ranking gives the standings in the form shared with distance events:
+// the best height as the mark, NM for athletes out without one.
+func (v *VerticalJump) Ranking() []Standing {
+	vs := v.Standings()
+	ranking := make([]Standing, len(vs))
+	for i, s := range vs {
+		ranking[i] = Standing{Rank: s.Rank, Bib: s.Bib}
+		switch {
+		case s.Best > 0:
+			ranking[i].Best = FormatHeight(s.Best)
+			if s.Retired {
+				ranking[i].Status = StatusRetired
+			}
+		case s.finished():
+			ranking[i].Status = StatusNM
+		}
+	}
+	return ranking
+}
+
+// tiedForFirst returns the leaders when they are level on countback and all
+// have finished competing, i.e. when a jump-off is due.
+func (v *VerticalJump) tiedForFirst(standings []VerticalStanding) []string {
+	if len(standings) < 2 || standings[0].Best == 0 {
+		return nil
+	}
+	var bibs []string
+	for _, s := range standings {
+		if !sameCountback(standings[0], s) {
+			break
+		}
+		if !s.finished() {
+			return nil
+		}
+		bibs = append(bibs, s.Bib)
+	}
+	if len(bibs) < 2 {
+		return nil
+	}
+	return bibs
+}
+
+// --- Jump-off ---
+// Each athlete tied for first has one attempt per height. The bar goes up
+// when more than one clears and down when all fail, until one athlete is left.
+type jumpOff struct {
+	step   int
+	height int
+	in     []string
+	rounds []jumpOffRound
+	// outIn is the round each athlete dropped out in; later is better.
+	outIn  map[string]int
+	winner string
+}
+type jumpOffRound struct {
+	height  int
+	results map[string]string
+}
+
+func (v *VerticalJump) StartJumpOff() error {
+	if v.jumpOff != nil {
+		return fmt.Errorf("the jump-off has already started")
+	}
+	bibs := v.tiedForFirst(v.Standings())
+	if bibs == nil {
+		return fmt.Errorf("no jump-off is needed")
+	}
+	step, _ := JumpOffStep(v.Discipline)
+	best, _ := v.athlete(bibs[0])
+	start := best.standing().Best + step
+	for _, h := range v.heights {
+		if h > best.standing().Best {
+			start = h
+			break
+		}
+	}
+	v.jumpOff = &jumpOff{step: step, height: start, in: bibs, outIn: make(map[string]int)}
+	v.jumpOff.rounds = []jumpOffRound{{height: start, results: make(map[string]string)}}
+	return nil
+}
+
+// JumpOffStarted reports whether StartJumpOff has been called.
+func (v *VerticalJump) JumpOffStarted() bool { return v.jumpOff != nil }
+
+// JumpOffHeight is the current jump-off bar; ok is false when there is no
+// jump-off in progress.
+func (v *VerticalJump) JumpOffHeight() (cm int, ok bool) {
+	if v.jumpOff == nil || v.jumpOff.winner != "" {
+		return 0, false
+	}
+	return v.jumpOff.height, true
+}
+
+// RecordJumpOff records bib's single attempt at the current jump-off height.
+// A pass forfeits the right to compete for first place (WA 26.9.4).
+func (v *VerticalJump) RecordJumpOff(bib, result string) error {
+	j := v.jumpOff
+	if j == nil || j.winner != "" {
+		return fmt.Errorf("no jump-off is in progress")
+	}
+	if result != Cleared && result != Failed && result != Passed {
+		return fmt.Errorf("invalid attempt '%s': use O, X or -", result)
+	}
+	round := &j.rounds[len(j.rounds)-1]
+	if !contains(j.in, bib) {
+		return fmt.Errorf("bib %s is not in the jump-off", bib)
+	}
+	if _, done := round.results[bib]; done {
+		return fmt.Errorf("bib %s has already jumped at %s", bib, FormatHeight(round.height))
+	}
+	round.results[bib] = result
+	if len(round.results) < len(j.in) {
+		return nil
+	}
+	var cleared, stillIn []string
+	for _, b := range j.in {
+		switch round.results[b] {
+		case Cleared:
+			cleared = append(cleared, b)
+			stillIn = append(stillIn, b)
+		case Failed:
+			stillIn = append(stillIn, b)
+		default:
+			j.outIn[b] = len(j.rounds) - 1
+		}
+	}
+	next := j.height + j.step
+	if len(cleared) > 0 {
+		for _, b := range stillIn {
+			if !contains(cleared, b) {
+				j.outIn[b] = len(j.rounds)
+			}
+		}
+		stillIn = cleared
+	} else {
+		next = j.height - j.step
+	}
+	j.in = stillIn
+	switch len(j.in) {
+	case 0:
+		// Everyone passed: the tie stands.
+		j.winner = "-"
+		return nil
+	case 1:
+		j.winner = j.in[0]
+		return nil
+	}
+	j.height = next
+	j.rounds = append(j.rounds, jumpOffRound{height: next, results: make(map[string]string)})
+	return nil
+}
+
+// jumpOffPlace sorts jump-off athletes: the winner first, then by the round
+// they dropped out in. Athletes outside the jump-off all compare equal.
+func (v *VerticalJump) jumpOffPlace(bib string) int {
+	j := v.jumpOff
+	if j == nil || j.winner == "" {
+		return 0
+	}
+	if bib == j.winner {
+		return -math.MaxInt32
+	}
+	if round, ok := j.outIn[bib]; ok {
+		return -round
+	}
+	return 0
+}
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package competition
+
+import (
+	"reflect"
+	"testing"
+
+	"PolyField/api"
+)
+
+// card records a whole series: cards["bib"] lists attempt strings per height.
+func playVertical(t *testing.T, v *VerticalJump, cards map[string][]string) {
+	t.Helper()
+	heights := v.Heights()
+	for bib, card := range cards {
+		for i, attempts := range card {
+			for _, c := range attempts {
+				if err := v.Record(bib, heights[i], string(c)); err != nil {
+					t.Fatalf("bib %s at %s: %v", bib, FormatHeight(heights[i]), err)
+				}
+			}
+		}
+	}
+}
+
+func TestVerticalCountback(t *testing.T) {
+	v, err := NewVerticalJump("HJ", []int{175, 180, 184, 188}, []string{"A", "B", "C", "D", "E"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	playVertical(t, v, map[string][]string{
+		"A": {"O", "XO", "O", "XXX"},
+		"B": {"-", "O", "XO", "XXX"},
+		"C": {"O", "O", "O", "XXX"},
+		"D": {"XO", "O", "O", "XXX"},
+		"E": {"XXX"},
+	})
+	var got []string
+	ranks := map[string]int{}
+	for _, s := range v.Standings() {
+		got = append(got, s.Bib)
+		ranks[s.Bib] = s.Rank
+		if s.JumpOff {
+			t.Errorf("bib %s flagged for a jump-off", s.Bib)
+		}
+	}
+	if want := []string{"C", "A", "D", "B", "E"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("order %v, want %v", got, want)
+	}
+	if want := map[string]int{"C": 1, "A": 2, "D": 2, "B": 4, "E": 0}; !reflect.DeepEqual(ranks, want) {
+		t.Fatalf("ranks %v, want %v", ranks, want)
+	}
+}
+
+func TestVerticalRecordRules(t *testing.T) {
+	v, _ := NewVerticalJump("PV", []int{400, 420, 440}, []string{"1", "2"})
+	playVertical(t, v, map[string][]string{"1": {"XX-", "X"}, "2": {"-", "O"}})
+	cases := []struct {
+		bib    string
+		height int
+		result string
+	}{
+		{"1", 440, Failed},  // XX- then X: three in a row
+		{"2", 400, Failed},  // already moved on
+		{"2", 420, Failed},  // already cleared
+		{"2", 430, Cleared}, // not in the progression
+		{"2", 440, "P"},     // not an attempt code
+		{"9", 440, Cleared}, // unknown bib
+	}
+	for _, c := range cases {
+		if err := v.Record(c.bib, c.height, c.result); err == nil {
+			t.Errorf("Record(%s, %d, %s) accepted", c.bib, c.height, c.result)
+		}
+	}
+	if err := v.AddHeight(440); err == nil {
+		t.Error("AddHeight accepted a height that is not above the last")
+	}
+	records, _ := v.Records("1")
+	want := []api.HeightRecord{{Height: "4.00", Attempts: "XX-"}, {Height: "4.20", Attempts: "X"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("records %+v, want %+v", records, want)
+	}
+}
+
+func TestVerticalJumpOff(t *testing.T) {
+	v, _ := NewVerticalJump("HJ", []int{175, 180, 184}, []string{"A", "C", "F"})
+	playVertical(t, v, map[string][]string{
+		"A": {"O", "O", "XXX"},
+		"C": {"O", "O"},
+		"F": {"XO", "XXX"},
+	})
+	if err := v.StartJumpOff(); err == nil {
+		t.Fatal("jump-off started while C is still competing")
+	}
+	playVertical(t, v, map[string][]string{"C": {"", "", "XXX"}})
+	for _, s := range v.Standings()[:2] {
+		if s.Rank != 1 || !s.JumpOff {
+			t.Fatalf("before the jump-off: %+v", s)
+		}
+	}
+	if err := v.StartJumpOff(); err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := v.JumpOffHeight(); !ok || h != 184 {
+		t.Fatalf("jump-off starts at %d, want 184", h)
+	}
+	for _, step := range []struct{ bib, result string }{{"A", Failed}, {"C", Failed}, {"A", Cleared}, {"C", Failed}} {
+		if err := v.RecordJumpOff(step.bib, step.result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := v.JumpOffHeight(); ok {
+		t.Fatal("jump-off still in progress after A cleared alone")
+	}
+	st := v.Standings()
+	if st[0].Bib != "A" || st[0].Rank != 1 || st[1].Bib != "C" || st[1].Rank != 2 || st[2].Rank != 3 {
+		t.Fatalf("after the jump-off: %+v", st)
+	}
+	records, _ := v.Records("A")
+	jumpOff := records[len(records)-2:]
+	want := []api.HeightRecord{{Height: "1.84", Attempts: "X", JumpOff: true}, {Height: "1.82", Attempts: "O", JumpOff: true}}
+	if !reflect.DeepEqual(jumpOff, want) {
+		t.Fatalf("jump-off records %+v, want %+v", jumpOff, want)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"PolyField/competition"
+	"PolyField/store"
+)
+
+// --- Standalone Competitions ---
+// While a competition is open, its events, vertical jump set-ups, result
+// cards, measurements and calibrations are written to the local store as
+// they change. Resuming replays them into a fresh session; syncing sends the
+// results to a PolyField server once one is reachable.
+const competitionsDirName = "competitions"
+
+// CreateCompetition starts a new competition and opens it, closing any
+// other.
+func (a *App) CreateCompetition(name string) (*CompetitionSummary, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("no competition store available")
+	}
+	c, err := a.db.Create(name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	a.dbMux.Lock()
+	a.openCompetition = c
+	a.dbMux.Unlock()
+	summary := c.Summary()
+	return &summary, nil
+}
+func (a *App) ListCompetitions() ([]CompetitionSummary, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("no competition store available")
+	}
+	return a.db.List()
+}
+
+// ResumeCompetition reopens a saved competition: calibrations, events,
+// vertical jumps, results and athlete statuses are restored as they were
+// last saved.
+func (a *App) ResumeCompetition(id string) (*CompetitionSummary, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("no competition store available")
+	}
+	c, err := a.db.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	// Replay with nothing open so the restored state is not written back.
+	a.CloseCompetition()
+	a.resetCompetitionState()
+	a.restoreCompetition(c)
+	a.dbMux.Lock()
+	a.openCompetition = c
+	a.dbMux.Unlock()
+	summary := c.Summary()
+	return &summary, nil
+}
+
+// CloseCompetition stops recording; everything is already saved.
+func (a *App) CloseCompetition() {
+	a.dbMux.Lock()
+	defer a.dbMux.Unlock()
+	a.openCompetition = nil
+}
+func (a *App) GetOpenCompetition() (*CompetitionSummary, error) {
+	a.dbMux.Lock()
+	defer a.dbMux.Unlock()
+	if a.openCompetition == nil {
+		return nil, fmt.Errorf("no competition open")
+	}
+	summary := a.openCompetition.Summary()
+	return &summary, nil
+}
+
+// SyncCompetition posts every result of the open competition to the server
+// and returns how many it took. Cards are complete, so sending one again is
+// harmless.
+func (a *App) SyncCompetition(ip string, port int) (int, error) {
+	a.dbMux.Lock()
+	if a.openCompetition == nil {
+		a.dbMux.Unlock()
+		return 0, fmt.Errorf("no competition open")
+	}
+	results := append([]ResultPayload(nil), a.openCompetition.Results...)
+	a.dbMux.Unlock()
+	host := net.JoinHostPort(ip, strconv.Itoa(port))
+	for i, payload := range results {
+		if err := a.client.PostResult(host, payload); err != nil {
+			return i, fmt.Errorf("sync stopped after %d of %d results: %w", i, len(results), err)
+		}
+	}
+	now := time.Now()
+	a.updateCompetition(func(c *store.Competition) { c.SyncedAt = &now })
+	return len(results), nil
+}
+
+// updateCompetition applies change to the open competition, if any, and
+// saves it. dbMux is never held while taking another lock.
+func (a *App) updateCompetition(change func(c *store.Competition)) {
+	a.dbMux.Lock()
+	defer a.dbMux.Unlock()
+	if a.openCompetition == nil || a.db == nil {
+		return
+	}
+	change(a.openCompetition)
+	if err := a.db.Save(a.openCompetition, time.Now()); err != nil {
+		log.Printf("Could not save competition %s: %v", a.openCompetition.ID, err)
+	}
+}
+func (a *App) saveCalibration(cal EDMCalibrationData) {
+	a.calibrations.Save(cal)
+	a.updateCompetition(func(c *store.Competition) { c.PutCalibration(cal) })
+}
+
+// saveEvent records the event with the flights and combined-events settings
+// made since it was imported, so that resuming restores them.
+func (a *App) saveEvent(eventID string) {
+	a.competitionMux.Lock()
+	event, ok := a.currentEvent(eventID)
+	a.competitionMux.Unlock()
+	if ok {
+		a.updateCompetition(func(c *store.Competition) { c.PutEvent(event) })
+	}
+}
+
+// currentEvent must be called with competitionMux held.
+func (a *App) currentEvent(eventID string) (Event, bool) {
+	var event Event
+	if ve, ok := a.verticals[eventID]; ok {
+		event = ve.event
+	} else if he, ok := a.horizontals[eventID]; ok && he.event.ID != "" {
+		event = he.event
+		event.Flights = append([]Flight(nil), he.flights...)
+	} else {
+		return Event{}, false
+	}
+	if spec, ok := a.combinedEvents[eventID]; ok {
+		event.Combined = &spec
+	}
+	return event, true
+}
+
+// saveVertical records the event's vertical jump set-up.
+func (a *App) saveVertical(eventID string) {
+	a.competitionMux.Lock()
+	ve, ok := a.verticals[eventID]
+	if !ok {
+		a.competitionMux.Unlock()
+		return
+	}
+	v := store.Vertical{EventID: eventID, Discipline: ve.jump.Discipline, Heights: ve.jump.Heights(),
+		Retired: ve.jump.Retired(), JumpOff: ve.jump.JumpOffStarted()}
+	event, _ := a.currentEvent(eventID)
+	a.competitionMux.Unlock()
+	a.updateCompetition(func(c *store.Competition) {
+		c.PutEvent(event)
+		c.PutVertical(v)
+	})
+}
+
+// recordMeasurement keeps a device reading with the event and athlete the
+// device is measuring.
+func (a *App) recordMeasurement(deviceID, kind, value string) {
+	eventID, bib := a.measuredAthlete(deviceID)
+	m := store.Measurement{DeviceID: deviceID, Kind: kind, Value: value, EventID: eventID, Bib: bib, At: time.Now()}
+	a.updateCompetition(func(c *store.Competition) { c.Measurements = append(c.Measurements, m) })
+}
+
+// resetCompetitionState forgets the session's events, results, statuses and
+// scoring, so that a resumed competition holds only what it saved.
+func (a *App) resetCompetitionState() {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	a.verticals = make(map[string]*verticalEvent)
+	a.horizontals = make(map[string]*horizontalEvent)
+	a.combinedEvents = make(map[string]CombinedEvent)
+	a.combinedPoints = make(map[string]map[string]map[string]int)
+	a.mastersEvents = make(map[string]*mastersEvent)
+	a.bests = make(map[string]*competition.EventBests)
+}
+
+// restoreCompetition replays a saved competition into the session.
+func (a *App) restoreCompetition(c *store.Competition) {
+	for _, cal := range c.Calibrations {
+		a.calibrations.Save(cal)
+	}
+	events := make(map[string]Event, len(c.Events))
+	for _, event := range c.Events {
+		events[event.ID] = event
+		a.trackEvent(event)
+	}
+	for _, v := range c.Verticals {
+		heights := make([]float64, len(v.Heights))
+		for i, cm := range v.Heights {
+			heights[i] = float64(cm) / 100
+		}
+		if _, err := a.StartVerticalJump(events[v.EventID], v.Discipline, heights); err != nil {
+			log.Printf("Could not restore vertical jump %s: %v", v.EventID, err)
+			continue
+		}
+		a.competitionMux.Lock()
+		restoreVertical(a.verticals[v.EventID].jump, v, c.Results)
+		a.competitionMux.Unlock()
+	}
+	for _, result := range c.Results {
+		if err := a.prepareResult(&result); err != nil {
+			log.Printf("Could not restore bib %s in %s: %v", result.AthleteBib, result.EventID, err)
+			continue
+		}
+		a.recordResult(result)
+	}
+	for _, st := range c.Statuses {
+		if _, err := a.SetAthleteStatus(st.EventID, st.Bib, st.Status); err != nil {
+			log.Printf("Could not restore the status of bib %s in %s: %v", st.Bib, st.EventID, err)
+		}
+	}
+}
+
+// restoreVertical replays the athletes' cards into jump: the heights first,
+// then the retirements, then the jump-off a round at a time. Every athlete
+// still in the jump-off has one attempt on their card for each round.
+func restoreVertical(jump *competition.VerticalJump, v store.Vertical, results []ResultPayload) {
+	jumpOffs := make(map[string][]string)
+	for _, result := range results {
+		if result.EventID != v.EventID {
+			continue
+		}
+		for _, h := range result.Heights {
+			if h.JumpOff {
+				jumpOffs[result.AthleteBib] = append(jumpOffs[result.AthleteBib], h.Attempts)
+				continue
+			}
+			cm, ok := competition.ParseMark(h.Height)
+			if !ok {
+				continue
+			}
+			for _, attempt := range h.Attempts {
+				if err := jump.Record(result.AthleteBib, cm, string(attempt)); err != nil {
+					log.Printf("Could not restore bib %s at %s in %s: %v", result.AthleteBib, h.Height, v.EventID, err)
+				}
+			}
+		}
+	}
+	for _, bib := range v.Retired {
+		if err := jump.Retire(bib); err != nil {
+			log.Printf("Could not restore the retirement of bib %s in %s: %v", bib, v.EventID, err)
+		}
+	}
+	if !v.JumpOff {
+		return
+	}
+	if err := jump.StartJumpOff(); err != nil {
+		log.Printf("Could not restore the jump-off in %s: %v", v.EventID, err)
+		return
+	}
+	for round := 0; ; round++ {
+		recorded := false
+		for _, result := range results {
+			attempts := jumpOffs[result.AthleteBib]
+			if result.EventID != v.EventID || round >= len(attempts) {
+				continue
+			}
+			if err := jump.RecordJumpOff(result.AthleteBib, attempts[round]); err != nil {
+				log.Printf("Could not restore bib %s in the %s jump-off: %v", result.AthleteBib, v.EventID, err)
+			}
+			recorded = true
+		}
+		if !recorded {
+			return
+		}
+	}
+}
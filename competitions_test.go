@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"PolyField/store"
+)
+
+func newCompetitionTestApp(t *testing.T, dir string) *App {
+	t.Helper()
+	a := newTestApp(t)
+	var err error
+	if a.db, err = store.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestCompetitionResumesAndSyncs(t *testing.T) {
+	dir := t.TempDir()
+	a := newCompetitionTestApp(t, dir)
+	created, err := a.CreateCompetition("Club Championships")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := filepath.Join(t.TempDir(), "shot.csv")
+	os.WriteFile(list, []byte("bib,order,name\n20,1,Dan Hale\n21,2,Eve Hart\n"), 0644)
+	if _, err := a.ImportStartList(list, "sp-m", "Shot Put Men"); err != nil {
+		t.Fatal(err)
+	}
+	a.submitResult(ResultPayload{EventID: "sp-m", AthleteBib: "21", Series: []Performance{{Attempt: 1, Mark: "11.40", Valid: true}}})
+	a.submitResult(ResultPayload{EventID: "sp-m", AthleteBib: "20", Series: []Performance{{Attempt: 1, Mark: "10.95", Valid: true}}})
+	a.submitResult(ResultPayload{EventID: "sp-m", AthleteBib: "20", Series: []Performance{{Attempt: 1, Mark: "10.95", Valid: true}, {Attempt: 2, Mark: "11.62", Valid: true}}})
+	hj := Event{ID: "hj-w", Name: "High Jump Women", Athletes: []Athlete{{Bib: "7", Order: 1}, {Bib: "12", Order: 2}}}
+	if _, err := a.StartVerticalJump(hj, "HJ", []float64{1.50, 1.55}); err != nil {
+		t.Fatal(err)
+	}
+	a.AddVerticalHeight("hj-w", 1.60)
+	for _, r := range []string{"X", "O"} {
+		a.RecordVerticalAttempt("hj-w", "7", 1.50, r)
+	}
+	a.saveCalibration(EDMCalibrationData{DeviceID: "edm", SelectedCircleType: "DISCUS", IsCentreSet: true})
+	wantStandings := a.GetStandings("sp-m")
+	wantVertical, _ := a.GetVerticalJump("hj-w")
+	a.CloseCompetition()
+	if _, err := a.GetOpenCompetition(); err == nil {
+		t.Fatal("competition still open after closing")
+	}
+
+	b := newCompetitionTestApp(t, dir)
+	saved, err := b.ListCompetitions()
+	if err != nil || len(saved) != 1 || saved[0].ID != created.ID || saved[0].Results != 3 {
+		t.Fatalf("saved competitions %+v, %v", saved, err)
+	}
+	if _, err := b.ResumeCompetition(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.GetStandings("sp-m"); !reflect.DeepEqual(got, wantStandings) || got[0].Bib != "20" {
+		t.Fatalf("resumed standings %+v, want %+v", got, wantStandings)
+	}
+	if got, _ := b.GetVerticalJump("hj-w"); !reflect.DeepEqual(got, wantVertical) {
+		t.Fatalf("resumed vertical jump %+v, want %+v", got, wantVertical)
+	}
+	if cal, ok := b.calibrations.Get("edm"); !ok || cal.SelectedCircleType != "DISCUS" {
+		t.Fatalf("calibration not restored: %+v", cal)
+	}
+
+	srv := newFakePolyFieldServer(t, nil)
+	ip, port := srv.hostPort()
+	n, err := b.SyncCompetition(ip, port)
+	if err != nil || n != 3 {
+		t.Fatalf("synced %d results: %v", n, err)
+	}
+	if got := srv.results(); len(got) != 3 {
+		t.Fatalf("server received %d results", len(got))
+	}
+	if summary, _ := b.GetOpenCompetition(); summary.SyncedAt == nil {
+		t.Fatal("sync time not recorded")
+	}
+}
+
+func TestCompetitionResumesStatusesAndJumpOff(t *testing.T) {
+	dir := t.TempDir()
+	a := newCompetitionTestApp(t, dir)
+	created, err := a.CreateCompetition("County Schools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := Event{ID: "sp-g", Athletes: []Athlete{{Bib: "30", Order: 1}, {Bib: "31", Order: 2}}}
+	if err := a.registerEvent(sp); err != nil {
+		t.Fatal(err)
+	}
+	a.submitResult(ResultPayload{EventID: "sp-g", AthleteBib: "30", Series: []Performance{{Attempt: 1, Mark: "9.80", Valid: true}}})
+	if _, err := a.SetAthleteStatus("sp-g", "31", "DNS"); err != nil {
+		t.Fatal(err)
+	}
+	hj := Event{ID: "hj-g", Athletes: []Athlete{{Bib: "7", Order: 1}, {Bib: "8", Order: 2}, {Bib: "9", Order: 3}}}
+	if _, err := a.StartVerticalJump(hj, "HJ", []float64{1.50, 1.55}); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []struct {
+		bib    string
+		height float64
+		result string
+	}{{"7", 1.50, "O"}, {"8", 1.50, "O"}, {"9", 1.50, "X"}, {"7", 1.55, "X"}, {"8", 1.55, "X"}, {"7", 1.55, "X"}, {"8", 1.55, "X"}, {"7", 1.55, "X"}, {"8", 1.55, "X"}} {
+		if _, err := a.RecordVerticalAttempt("hj-g", step.bib, step.height, step.result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.RetireVerticalAthlete("hj-g", "9"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.StartVerticalJumpOff("hj-g"); err != nil {
+		t.Fatal(err)
+	}
+	// One full round, then the next round part-way through.
+	for _, step := range [][2]string{{"7", "O"}, {"8", "O"}, {"7", "X"}} {
+		if _, err := a.RecordVerticalJumpOff("hj-g", step[0], step[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantStandings := a.GetStandings("sp-g")
+	wantVertical, _ := a.GetVerticalJump("hj-g")
+	a.CloseCompetition()
+
+	b := newCompetitionTestApp(t, dir)
+	if _, err := b.ResumeCompetition(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.GetStandings("sp-g"); !reflect.DeepEqual(got, wantStandings) || got[1].Status != "DNS" {
+		t.Fatalf("resumed standings %+v, want %+v", got, wantStandings)
+	}
+	got, _ := b.GetVerticalJump("hj-g")
+	if !reflect.DeepEqual(got, wantVertical) || got.JumpOffHeight == "" {
+		t.Fatalf("resumed vertical jump %+v, want %+v", got, wantVertical)
+	}
+	if _, err := b.RecordVerticalJumpOff("hj-g", "8", "O"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := b.GetVerticalJump("hj-g"); got.Standings[0].Bib != "8" || got.JumpOffHeight != "" {
+		t.Fatalf("jump-off not decided after resuming: %+v", got)
+	}
+}
+
+func TestMeasurementsAreRecordedForTheirStation(t *testing.T) {
+	a := newCompetitionTestApp(t, t.TempDir())
+	if _, err := a.CreateCompetition("Open Throws"); err != nil {
+		t.Fatal(err)
+	}
+	a.SetOverlayEvent(Event{ID: "sp-a"})
+	a.SetOverlayAthlete("1", 1)
+	if err := a.SaveStation(Station{Name: "Circle B", EDM: "edm-b", Wind: "wind-b", EventID: "sp-b"}); err != nil {
+		t.Fatal(err)
+	}
+	a.SetStationAthlete("Circle B", "2")
+	a.recordMeasurement("edm-b", "throw", "14.20 m")
+	a.recordMeasurement("wind-b", "wind", "+0.3 m/s")
+	a.recordMeasurement("edm", "throw", "12.00 m")
+	var got []string
+	for _, m := range a.openCompetition.Measurements {
+		got = append(got, m.DeviceID+" "+m.EventID+" "+m.Bib)
+	}
+	want := []string{"edm-b sp-b 2", "wind-b sp-b 2", "edm sp-a 1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("measurements recorded as %v, want %v", got, want)
+	}
+}
+
+func TestCompetitionResumesFlightsAndCombinedSettings(t *testing.T) {
+	dir := t.TempDir()
+	a := newCompetitionTestApp(t, dir)
+	created, err := a.CreateCompetition("Combined Events Open")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var athletes []Athlete
+	for i, bib := range []string{"1", "2", "3", "4"} {
+		athletes = append(athletes, Athlete{Bib: bib, Order: i + 1})
+	}
+	if err := a.registerEvent(Event{ID: "sp-w", Athletes: athletes}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetCombinedEvent("sp-w", CombinedEvent{Competition: "hep", Sex: "W", Discipline: "SP"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SplitEventFlights("sp-w", 2); err != nil {
+		t.Fatal(err)
+	}
+	for i, bib := range []string{"1", "2", "3", "4"} {
+		mark := fmt.Sprintf("1%d.00", i)
+		a.submitResult(ResultPayload{EventID: "sp-w", AthleteBib: bib, Series: []Performance{
+			{Attempt: 1, Mark: mark, Unit: "m", Valid: true}, {Attempt: 2, Mark: "FOUL", Unit: "m"}, {Attempt: 3, Mark: "FOUL", Unit: "m"}}})
+	}
+	if _, err := a.MergeFlightsToFinal("sp-w", EventRules{CutEnabled: true, CutQualifiers: 2, ReorderAfterCut: true}); err != nil {
+		t.Fatal(err)
+	}
+	wantFlights := a.GetEventFlights("sp-w")
+	wantCombined := a.GetCombinedStandings("hep")
+	a.CloseCompetition()
+
+	b := newCompetitionTestApp(t, dir)
+	if _, err := b.ResumeCompetition(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.GetEventFlights("sp-w"); !reflect.DeepEqual(got, wantFlights) || len(got) != 3 {
+		t.Fatalf("resumed flights %+v, want %+v", got, wantFlights)
+	}
+	if turn, err := b.NextInFlight("sp-w", "Final", 6); err != nil || turn.Bib != "3" || turn.Attempt != 4 {
+		t.Fatalf("first in the resumed final: %+v, %v", turn, err)
+	}
+	if got := b.GetCombinedStandings("hep"); !reflect.DeepEqual(got, wantCombined) || len(got.Totals) != 4 {
+		t.Fatalf("resumed combined standings %+v, want %+v", got, wantCombined)
+	}
+}
+
+func TestResumeDropsTheSessionsEarlierState(t *testing.T) {
+	dir := t.TempDir()
+	a := newCompetitionTestApp(t, dir)
+	first, err := a.CreateCompetition("Day One")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.registerEvent(Event{ID: "sp-a", Athletes: []Athlete{{Bib: "1", Order: 1}, {Bib: "2", Order: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+	a.SetCombinedEvent("sp-a", CombinedEvent{Competition: "hep", Sex: "W", Discipline: "SP"})
+	a.submitResult(ResultPayload{EventID: "sp-a", AthleteBib: "2", Series: []Performance{{Attempt: 1, Mark: "12.00", Unit: "m", Valid: true}}})
+	a.SetAthleteStatus("sp-a", "1", "DNS")
+	a.CloseCompetition()
+	b := newCompetitionTestApp(t, dir)
+	second, err := b.CreateCompetition("Day Two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.registerEvent(Event{ID: "sp-b", Athletes: []Athlete{{Bib: "3", Order: 1}}})
+
+	if _, err := b.ResumeCompetition(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.GetStandings("sp-b"); len(got) != 0 {
+		t.Fatalf("day two's event still live after resuming day one: %+v", got)
+	}
+	if got := b.GetStandings("sp-a"); len(got) != 2 || got[1].Status != "DNS" {
+		t.Fatalf("day one standings %+v", got)
+	}
+	if _, err := b.ResumeCompetition(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.GetStandings("sp-a"); len(got) != 0 {
+		t.Fatalf("day one's event still live after resuming day two: %+v", got)
+	}
+	if got := b.GetCombinedStandings("hep"); len(got.Totals) != 0 {
+		t.Fatalf("day one's combined totals still live: %+v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"PolyField/devices"
+	"PolyField/measurement"
+	"PolyField/resultqueue"
+)
+
+// fakeEDM answers read commands on one end of a net.Pipe and records whether
+// a second command ever arrived before the previous one was answered.
+type fakeEDM struct {
+	conn        net.Conn
+	interleaved atomic.Bool
+	commands    atomic.Int32
+}
+
+func newFakeEDM(t *testing.T) (*fakeEDM, net.Conn) {
+	t.Helper()
+	client, server := net.Pipe()
+	f := &fakeEDM{conn: server}
+	go f.serve()
+	t.Cleanup(func() { client.Close(); server.Close() })
+	return f, client
+}
+func (f *fakeEDM) serve() {
+	buf := make([]byte, len(measurement.ReadCommand))
+	for {
+		f.conn.SetReadDeadline(time.Time{})
+		if _, err := io.ReadFull(f.conn, buf); err != nil {
+			return
+		}
+		if !bytes.Equal(buf, measurement.ReadCommand) {
+			f.interleaved.Store(true)
+		}
+		f.commands.Add(1)
+		// Anything arriving while this command is being "measured" means two
+		// callers are talking to the EDM at once.
+		f.conn.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+		var extra [1]byte
+		if n, _ := f.conn.Read(extra[:]); n > 0 {
+			f.interleaved.Store(true)
+		}
+		f.conn.SetReadDeadline(time.Time{})
+		if _, err := fmt.Fprintf(f.conn, "10000 0900000 0450000 0\r\n"); err != nil {
+			return
+		}
+	}
+}
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	a := NewApp()
+	openResultCache(a, filepath.Join(t.TempDir(), cacheFileName))
+	return a
+}
+func openResultCache(a *App, path string) {
+	a.results = resultqueue.New(path)
+	a.results.Load()
+}
+func attachTestDevice(a *App, deviceID, devType string, conn io.ReadWriteCloser) *Device {
+	dev := &Device{Conn: conn, Type: devType, ConnectionType: "network", ReadTerminator: devices.DefaultReadTerm, WriteTerminator: devices.DefaultWriteTerm}
+	a.devicesMux.Lock()
+	a.attachDevice(deviceID, dev)
+	a.devicesMux.Unlock()
+	return dev
+}
+
+func TestConcurrentMeasureThrowDoesNotInterleave(t *testing.T) {
+	a := newTestApp(t)
+	edm, conn := newFakeEDM(t)
+	attachTestDevice(a, "edm", "edm", conn)
+	if err := a.SaveCalibration("edm", EDMCalibrationData{DeviceID: "edm", SelectedCircleType: "SHOT", TargetRadius: UkaRadiusShot, IsCentreSet: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	const callers = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := a.MeasureThrow("edm"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	// Exercise the other locks while measurements are in flight.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			a.GetCalibration("edm")
+			a.ListDevices()
+			a.GetDeviceStatuses()
+			a.checkDeviceHealth()
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("MeasureThrow: %v", err)
+	}
+	if edm.interleaved.Load() {
+		t.Fatal("commands from concurrent measurements were interleaved on the EDM link")
+	}
+	if got := edm.commands.Load(); got != 2*callers {
+		t.Fatalf("EDM saw %d commands, want %d", got, 2*callers)
+	}
+}
+
+func TestTransactAfterCloseFails(t *testing.T) {
+	a := newTestApp(t)
+	_, conn := newFakeEDM(t)
+	dev := attachTestDevice(a, "edm-circle-A", "edm", conn)
+	if _, err := a.DisconnectDevice("edm-circle-A"); err != nil {
+		t.Fatal(err)
+	}
+	err := dev.Transact(func(io.ReadWriteCloser) error { return nil })
+	if err != devices.ErrClosed {
+		t.Fatalf("Transact after close = %v, want devices.ErrClosed", err)
+	}
+}
+
+func TestFlushResultCacheKeepsResultsAddedDuringRetry(t *testing.T) {
+	a := newTestApp(t)
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	a.serverAddress = srv.Listener.Addr().String()
+	a.results.Add(ResultPayload{EventID: "e1", AthleteBib: "1"})
+
+	done := make(chan struct{})
+	go func() {
+		a.flushResultCache()
+		close(done)
+	}()
+	<-arrived
+	// The cache must stay writable while the flush is blocked on the server.
+	added := make(chan struct{})
+	go func() {
+		a.results.Add(ResultPayload{EventID: "e1", AthleteBib: "2"})
+		close(added)
+	}()
+	select {
+	case <-added:
+	case <-time.After(2 * time.Second):
+		t.Fatal("results.Add blocked behind flushResultCache")
+	}
+	close(release)
+	<-done
+
+	if c := a.results.Pending(); len(c) != 1 || c[0].AthleteBib != "2" {
+		t.Fatalf("cache after flush = %+v, want only bib 2", c)
+	}
+	if received.Load() != 1 {
+		t.Fatalf("server received %d results, want 1", received.Load())
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	ossignal "os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// --- Headless Daemon ---
+// The daemon runs the App without a window and exposes the same operations
+// over a local HTTP API. The command-line subcommands are clients of it, so
+// connections and calibrations live as long as the daemon does.
+const (
+	defaultDaemonAddress = "127.0.0.1:7411"
+	daemonShutdownWait   = 5 * time.Second
+)
+
+type connectRequest struct {
+	Port    string `json:"port,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+type circleRequest struct {
+	CircleType string `json:"circleType"`
+}
+type statusMessage struct {
+	Message string `json:"message"`
+}
+type markResponse struct {
+	Mark string `json:"mark"`
+}
+type windResponse struct {
+	Wind string `json:"wind"`
+}
+type queueStatus struct {
+	Pending int             `json:"pending"`
+	Results []ResultPayload `json:"results"`
+}
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func (a *App) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	a.measurementRoutes(mux)
+	mux.HandleFunc("GET /api/v1/ports", func(w http.ResponseWriter, r *http.Request) {
+		ports, err := a.ListSerialPorts()
+		writeJSON(w, ports, err)
+	})
+	mux.HandleFunc("POST /api/v1/devices/{id}/connect", func(w http.ResponseWriter, r *http.Request) {
+		var req connectRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		msg, err := a.connectFromRequest(r.PathValue("id"), req)
+		writeJSON(w, statusMessage{Message: msg}, err)
+	})
+	mux.HandleFunc("POST /api/v1/devices/{id}/disconnect", func(w http.ResponseWriter, r *http.Request) {
+		msg, err := a.DisconnectDevice(r.PathValue("id"))
+		writeJSON(w, statusMessage{Message: msg}, err)
+	})
+	mux.HandleFunc("GET /api/v1/edm/{id}/calibration", func(w http.ResponseWriter, r *http.Request) {
+		cal, err := a.GetCalibration(r.PathValue("id"))
+		writeJSON(w, cal, err)
+	})
+	mux.HandleFunc("POST /api/v1/edm/{id}/circle", func(w http.ResponseWriter, r *http.Request) {
+		var req circleRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		cal, err := a.SelectCircle(r.PathValue("id"), req.CircleType)
+		writeJSON(w, cal, err)
+	})
+	mux.HandleFunc("POST /api/v1/edm/{id}/centre", func(w http.ResponseWriter, r *http.Request) {
+		cal, err := a.SetCircleCentre(r.PathValue("id"))
+		writeJSON(w, cal, err)
+	})
+	mux.HandleFunc("POST /api/v1/edm/{id}/edge", func(w http.ResponseWriter, r *http.Request) {
+		cal, err := a.VerifyCircleEdge(r.PathValue("id"))
+		writeJSON(w, cal, err)
+	})
+	mux.HandleFunc("GET /api/v1/queue", func(w http.ResponseWriter, r *http.Request) {
+		pending := a.results.Pending()
+		writeJSON(w, queueStatus{Pending: len(pending), Results: pending}, nil)
+	})
+	return mux
+}
+
+// measurementRoutes are the operations shared with the remote trigger API:
+// reading device state and taking measurements. Results are also announced
+// to the UI, since whoever asked is not at the desk.
+func (a *App) measurementRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/devices", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, a.ListDevices(), nil)
+	})
+	mux.HandleFunc("GET /api/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, a.GetDeviceStatuses(), nil)
+	})
+	mux.HandleFunc("POST /api/v1/edm/{id}/measure", func(w http.ResponseWriter, r *http.Request) {
+		mark, err := a.MeasureThrow(r.PathValue("id"))
+		if err == nil {
+			a.emitEvent(remoteMeasurementEvent, RemoteMeasurement{DeviceID: r.PathValue("id"), Kind: "mark", Value: mark})
+		}
+		writeJSON(w, markResponse{Mark: mark}, err)
+	})
+	mux.HandleFunc("POST /api/v1/wind/{id}/measure", func(w http.ResponseWriter, r *http.Request) {
+		wind, err := a.MeasureWind(r.PathValue("id"))
+		if err == nil {
+			a.emitEvent(remoteMeasurementEvent, RemoteMeasurement{DeviceID: r.PathValue("id"), Kind: "wind", Value: wind})
+		}
+		writeJSON(w, windResponse{Wind: wind}, err)
+	})
+}
+
+// connectFromRequest opens a serial port when one is named, otherwise a
+// network connection to "host:port".
+func (a *App) connectFromRequest(deviceID string, req connectRequest) (string, error) {
+	switch {
+	case req.Port != "" && req.Profile != "":
+		return a.ConnectSerialDeviceWithProfile(deviceID, req.Port, req.Profile)
+	case req.Port != "":
+		return a.ConnectSerialDevice(deviceID, req.Port)
+	case req.Address != "":
+		host, portStr, err := net.SplitHostPort(req.Address)
+		if err != nil {
+			return "", fmt.Errorf("invalid address '%s': %w", req.Address, err)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return "", fmt.Errorf("invalid port in '%s'", req.Address)
+		}
+		return a.ConnectNetworkDevice(deviceID, host, port)
+	}
+	return "", fmt.Errorf("give a serial port or a network address")
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(apiError{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return false
+	}
+	return true
+}
+
+// writeJSON answers with v, or with the error when the operation failed.
+// Failures are reported as 409: the request was understood but the devices
+// were not in a state to carry it out.
+func writeJSON(w http.ResponseWriter, v interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		v = apiError{Error: err.Error()}
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+// runDaemon serves the API on addr until interrupted. A non-empty token is
+// required on every request.
+func runDaemon(addr string, demo bool, token string) error {
+	app := NewApp()
+	// The context carries no Wails events, so emitEvent stays quiet.
+	app.wailsStartup(context.Background())
+	app.SetDemoMode(demo)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not start daemon: %w", err)
+	}
+	handler := app.apiHandler()
+	if token != "" {
+		handler = requireToken(token, handler)
+	} else if tcp, ok := ln.Addr().(*net.TCPAddr); ok && !tcp.IP.IsLoopback() {
+		log.Printf("Warning: the daemon API is reachable from the network without a token")
+	}
+	server := &http.Server{Handler: handler}
+	ctx, stop := ossignal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), daemonShutdownWait)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	log.Printf("PolyField daemon listening on http://%s/", ln.Addr())
+	err = server.Serve(ln)
+	app.SetDemoMode(false)
+	app.wailsShutdown(context.Background())
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"PolyField/devices"
+	"PolyField/simulator"
+)
+
+// --- Demo Hardware ---
+// Demo mode runs simulated instruments on loopback TCP ports and connects to
+// them like any network device, so every reading goes through the real
+// protocol, consistency and geometry code.
+const (
+	demoHost             = "127.0.0.1"
+	demoThrowSector      = 15.0 // degrees either side of the sector centre line
+	demoSectorFoulMargin = 5.0  // degrees beyond the sector line a foul lands
+	demoEdgeErrorMm      = 3.0
+	demoWindSettleTime   = 500 * time.Millisecond
+	demoWindPoll         = 20 * time.Millisecond
+)
+
+type demoHardware struct {
+	mu         sync.Mutex
+	seed       int64
+	placements map[string]*rand.Rand
+	edms       map[string]*simulator.EDM
+	winds      map[string]*simulator.Wind
+	scoreboard map[string]*simulator.Scoreboard
+	endpoints  []*simulator.Endpoint
+	edmConfig  simulator.EDMConfig
+	windConfig simulator.WindConfig
+	scenario   *DemoScenario
+	step       int
+	// throwDistance picks how far beyond the circle the next implement lands,
+	// drawing from the EDM's placement stream. It is called with mu held.
+	throwDistance func(circleType string, rng *rand.Rand) float64
+}
+
+func newDemoHardware(seed int64) *demoHardware {
+	d := &demoHardware{
+		seed:       seed,
+		placements: make(map[string]*rand.Rand),
+		edms:       make(map[string]*simulator.EDM),
+		winds:      make(map[string]*simulator.Wind),
+		scoreboard: make(map[string]*simulator.Scoreboard),
+		edmConfig:  simulator.DefaultEDMConfig(),
+		windConfig: simulator.DefaultWindConfig(),
+	}
+	d.throwDistance = d.randomThrowDistance
+	return d
+}
+
+// deviceRand is one device's stream for one purpose, derived from the seed
+// alone: the order devices start in, and how often a wind gauge has reported,
+// never shift another device's draws.
+func (d *demoHardware) deviceRand(deviceID, purpose string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(deviceID + "/" + purpose))
+	return rand.New(rand.NewSource(d.seed ^ int64(h.Sum64())))
+}
+
+// placementRand must be called with d.mu held.
+func (d *demoHardware) placementRand(deviceID string) *rand.Rand {
+	rng, ok := d.placements[deviceID]
+	if !ok {
+		rng = d.deviceRand(deviceID, "placement")
+		d.placements[deviceID] = rng
+	}
+	return rng
+}
+func (d *demoHardware) randomThrowDistance(circleType string, rng *rand.Rand) float64 {
+	min, max := 15.00, 60.00
+	if circleType == "SHOT" {
+		min, max = 6.00, 15.00
+	}
+	return min + rng.Float64()*(max-min)
+}
+func (d *demoHardware) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ep := range d.endpoints {
+		ep.Close()
+	}
+	d.endpoints = nil
+}
+
+// SetDemoMode switches between real and simulated hardware. Enabling it
+// replaces the default edm, wind and scoreboard connections with simulators;
+// disabling it disconnects every simulated device.
+func (a *App) SetDemoMode(enabled bool) {
+	a.stateMux.Lock()
+	changed := a.demoMode != enabled
+	a.demoMode = enabled
+	a.stateMux.Unlock()
+	if !changed {
+		return
+	}
+	if enabled {
+		a.startDemoHardware(newDemoHardware(time.Now().UnixNano()))
+	} else {
+		a.stopDemoHardware()
+	}
+}
+func (a *App) startDemoHardware(demo *demoHardware) {
+	a.stateMux.Lock()
+	a.demo = demo
+	a.stateMux.Unlock()
+	for _, id := range []string{"edm", "wind", "scoreboard"} {
+		if _, err := a.demoDevice(id); err != nil {
+			log.Printf("DEMO: could not start simulated %s: %v", id, err)
+		}
+	}
+}
+func (a *App) stopDemoHardware() {
+	a.stateMux.Lock()
+	demo := a.demo
+	a.demo = nil
+	a.stateMux.Unlock()
+	if demo == nil {
+		return
+	}
+	demo.mu.Lock()
+	var ids []string
+	for id := range demo.edms {
+		ids = append(ids, id)
+	}
+	for id := range demo.winds {
+		ids = append(ids, id)
+	}
+	for id := range demo.scoreboard {
+		ids = append(ids, id)
+	}
+	demo.mu.Unlock()
+	a.devicesMux.Lock()
+	for _, id := range ids {
+		a.closeDevice(id)
+	}
+	a.devicesMux.Unlock()
+	demo.close()
+}
+func (a *App) demoHardware() *demoHardware {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	return a.demo
+}
+
+// demoDevice makes sure deviceID is connected to a simulator, starting one if
+// needed, and reports whether it had to. It is a no-op outside demo mode.
+func (a *App) demoDevice(deviceID string) (bool, error) {
+	demo := a.demoHardware()
+	if demo == nil {
+		return false, nil
+	}
+	devType, err := devices.TypeForID(deviceID)
+	if err != nil {
+		return false, err
+	}
+	demo.mu.Lock()
+	var handler simulator.Handler
+	switch devType {
+	case "edm":
+		if _, ok := demo.edms[deviceID]; !ok {
+			sim := simulator.NewEDM(demo.edmConfig, demo.deviceRand(deviceID, "readings"))
+			demo.edms[deviceID], handler = sim, sim
+		}
+	case "wind":
+		if _, ok := demo.winds[deviceID]; !ok {
+			sim := simulator.NewWind(demo.windConfig, demo.deviceRand(deviceID, "readings"))
+			demo.winds[deviceID], handler = sim, sim
+		}
+	case "scoreboard":
+		if _, ok := demo.scoreboard[deviceID]; !ok {
+			sim := simulator.NewScoreboard()
+			demo.scoreboard[deviceID], handler = sim, sim
+		}
+	}
+	if handler == nil {
+		demo.mu.Unlock()
+		if _, ok := a.device(deviceID); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("simulated %s is not connected", deviceID)
+	}
+	ep, err := simulator.ListenTCP(handler, net.JoinHostPort(demoHost, "0"))
+	if err == nil {
+		demo.endpoints = append(demo.endpoints, ep)
+	}
+	demo.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	host, portStr, _ := net.SplitHostPort(ep.Address)
+	port, _ := strconv.Atoi(portStr)
+	if _, err := a.ConnectNetworkDevice(deviceID, host, port); err != nil {
+		return false, err
+	}
+	log.Printf("DEMO: simulated %s listening on %s", deviceID, ep.Address)
+	return true, nil
+}
+
+// awaitDemoWind gives a simulated gauge that has just started, or had its
+// readings flushed, up to demoWindSettleTime to report.
+func (a *App) awaitDemoWind(deviceID string) {
+	deadline := time.Now().Add(demoWindSettleTime)
+	for time.Now().Before(deadline) {
+		a.windMux.Lock()
+		n := len(a.windBuffers[deviceID])
+		a.windMux.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(demoWindPoll)
+	}
+}
+
+// demoPlacePrism moves the simulated prism for an EDM before it is read.
+// place draws from that EDM's own stream, with d.mu held.
+func (a *App) demoPlacePrism(deviceID string, place func(d *demoHardware, rng *rand.Rand) (x, y float64)) error {
+	demo := a.demoHardware()
+	if demo == nil {
+		return nil
+	}
+	if _, err := a.demoDevice(deviceID); err != nil {
+		return err
+	}
+	demo.mu.Lock()
+	x, y := place(demo, demo.placementRand(deviceID))
+	sim := demo.edms[deviceID]
+	demo.mu.Unlock()
+	if sim != nil {
+		sim.Aim(x, y)
+	}
+	return nil
+}
+func demoCentre(*demoHardware, *rand.Rand) (float64, float64) { return 0, 0 }
+
+// demoEdge holds the prism on the circle edge with up to a few millimetres of
+// placement error, inside the tolerance so a demo calibration verifies. The
+// javelin arc is only ever measured in front.
+func demoEdge(cal EDMCalibrationData) func(d *demoHardware, rng *rand.Rand) (float64, float64) {
+	return func(d *demoHardware, rng *rand.Rand) (float64, float64) {
+		spread := math.Pi
+		if cal.SelectedCircleType == "JAVELIN_ARC" {
+			spread = math.Pi / 6
+		}
+		angle := (rng.Float64()*2 - 1) * spread
+		radius := cal.TargetRadius + (rng.Float64()*2-1)*demoEdgeErrorMm/1000
+		return radius * math.Cos(angle), radius * math.Sin(angle)
+	}
+}
+
+// demoLanding puts the prism where the next implement lands: inside the
+// sector, or just outside it for a scripted foul without a mark.
+func demoLanding(cal EDMCalibrationData) func(d *demoHardware, rng *rand.Rand) (float64, float64) {
+	return func(d *demoHardware, rng *rand.Rand) (float64, float64) {
+		dist := d.throwDistance(cal.SelectedCircleType, rng)
+		angle := (rng.Float64()*2 - 1) * demoThrowSector
+		if step := d.currentStep(); step != nil && step.Foul && step.Mark == 0 {
+			angle = math.Copysign(demoThrowSector+demoSectorFoulMargin*(1+rng.Float64()), angle)
+		}
+		r := cal.TargetRadius + dist
+		return r * math.Cos(angle*math.Pi/180), r * math.Sin(angle*math.Pi/180)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"PolyField/simulator"
+)
+
+func newDemoApp(t *testing.T) (*App, *demoHardware) {
+	t.Helper()
+	a := newTestApp(t)
+	a.SetDemoMode(true)
+	t.Cleanup(func() { a.SetDemoMode(false) })
+	demo := a.demoHardware()
+	if demo == nil {
+		t.Fatal("demo hardware did not start")
+	}
+	return a, demo
+}
+
+func TestDemoModeMeasuresThroughSimulators(t *testing.T) {
+	a, demo := newDemoApp(t)
+	cfg := simulator.DefaultEDMConfig()
+	cfg.NoiseMm = 0
+	demo.edms["edm"].SetConfig(cfg)
+	demo.throwDistance = func(string, *rand.Rand) float64 { return 12.34 }
+
+	cal, err := a.SetCircleCentre("edm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(cal.StationCoordinates.X-cfg.StationX) > 0.002 || math.Abs(cal.StationCoordinates.Y-cfg.StationY) > 0.002 {
+		t.Fatalf("station at %+v, want (%v, %v)", cal.StationCoordinates, cfg.StationX, cfg.StationY)
+	}
+	cal, err = a.VerifyCircleEdge("edm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.EdgeVerificationResult == nil || math.Abs(cal.EdgeVerificationResult.MeasuredRadius-UkaRadiusShot) > 0.02 {
+		t.Fatalf("edge verification %+v", cal.EdgeVerificationResult)
+	}
+	mark, err := a.MeasureThrow("edm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mark != "12.34 m" {
+		t.Fatalf("MeasureThrow = %q, want 12.34 m", mark)
+	}
+
+	time.Sleep(demoWindSettleTime)
+	wind, err := a.MeasureWind("wind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(wind, " m/s") {
+		t.Fatalf("MeasureWind = %q", wind)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		shown := strings.Join(demo.scoreboard["scoreboard"].Received(), "")
+		if strings.Contains(shown, "12.34") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("scoreboard never showed the mark, got %q", shown)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestDemoModeRejectsMalformedEDMReplies(t *testing.T) {
+	a, demo := newDemoApp(t)
+	cfg := simulator.DefaultEDMConfig()
+	cfg.MalformedRate = 1
+	demo.edms["edm"].SetConfig(cfg)
+	if _, err := a.SetCircleCentre("edm"); err == nil {
+		t.Fatal("expected malformed EDM reply to be rejected")
+	}
+}
+
+func TestDemoModeStartsSimulatorsForNamedDevices(t *testing.T) {
+	a, _ := newDemoApp(t)
+	if _, err := a.SetCircleCentre("edm-circle-B"); err != nil {
+		t.Fatal(err)
+	}
+	a.SetDemoMode(false)
+	if _, ok := a.device("edm-circle-B"); ok {
+		t.Fatal("simulated device still connected after leaving demo mode")
+	}
+}
+
+func TestDemoScenarioDrivesDevices(t *testing.T) {
+	path := filepath.Join("scenarios", "shot-put-training.json")
+	a := newTestApp(t)
+	t.Cleanup(func() { a.SetDemoMode(false) })
+	status, err := a.LoadDemoScenario(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Step != 1 || status.Athlete == nil || status.Athlete.Bib != "101" {
+		t.Fatalf("first step %+v", status)
+	}
+	if got := a.GetStandings("demo-sp"); len(got) != 3 {
+		t.Fatalf("scenario event not tracked: %+v", got)
+	}
+	if _, err := a.SetCircleCentre("edm"); err != nil {
+		t.Fatal(err)
+	}
+	if mark, err := a.MeasureThrow("edm"); err != nil || mark != "12.41 m" {
+		t.Fatalf("MeasureThrow = %q, %v; want 12.41 m", mark, err)
+	}
+	for status.Current.Dropout == nil {
+		if status, err = a.NextDemoScenarioStep(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg := a.demoHardware().edms["edm"].Config(); cfg.DropoutRate != 1 {
+		t.Fatalf("EDM dropout not applied: %+v", cfg)
+	}
+	if status, err = a.NextDemoScenarioStep(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := a.demoHardware().edms["edm"].Config(); cfg.DropoutRate != 0 {
+		t.Fatalf("EDM dropout not cleared: %+v", cfg)
+	}
+	if cfg := a.demoHardware().winds["wind"].Config(); cfg.Speed != 1.1 {
+		t.Fatalf("wind speed %v, want 1.1", cfg.Speed)
+	}
+}
+
+func TestDemoScenarioValidation(t *testing.T) {
+	s := DemoScenario{Event: Event{Athletes: []Athlete{{Bib: "1"}}}, Steps: []DemoScenarioStep{{Bib: "2", Attempt: 1, Mark: 10}}}
+	if err := s.validate(); err == nil {
+		t.Fatal("expected unknown bib to be rejected")
+	}
+	s.Steps[0] = DemoScenarioStep{Bib: "1", Attempt: 1}
+	if err := s.validate(); err == nil {
+		t.Fatal("expected a valid attempt without a mark to be rejected")
+	}
+}
+
+// The same scenario and the same actions give the same readings.
+func TestDemoScenarioIsReproducible(t *testing.T) {
+	run := func() []string {
+		a := newTestApp(t)
+		defer a.SetDemoMode(false)
+		status, err := a.LoadDemoScenario(filepath.Join("scenarios", "shot-put-training.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := a.SetCircleCentre("edm"); err != nil {
+			t.Fatal(err)
+		}
+		cal, err := a.VerifyCircleEdge("edm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{fmt.Sprintf("edge %.4f", cal.EdgeVerificationResult.MeasuredRadius)}
+		for status.Step <= 3 {
+			mark, err := a.MeasureThrow("edm")
+			if err != nil {
+				t.Fatal(err)
+			}
+			wind, err := a.MeasureWind("wind")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, mark, wind)
+			if status, err = a.NextDemoScenarioStep(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return got
+	}
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("runs differ:\n%v\n%v", first, second)
+	}
+	if first[1] != "12.41 m" || first[2] != "+0.4 m/s" || first[4] != "+0.6 m/s" || first[6] != "-0.2 m/s" {
+		t.Fatalf("readings %v", first)
+	}
+}
+
+func TestDemoScenarioFoulLandsOutsideSector(t *testing.T) {
+	d := newDemoHardware(1)
+	d.scenario = &DemoScenario{Steps: []DemoScenarioStep{{Bib: "1", Attempt: 1, Foul: true}}}
+	d.throwDistance = d.scenarioThrowDistance
+	land := demoLanding(EDMCalibrationData{SelectedCircleType: "SHOT", TargetRadius: UkaRadiusShot})
+	for i := 0; i < 20; i++ {
+		x, y := land(d, d.placementRand("edm"))
+		if angle := math.Abs(math.Atan2(y, x)) * 180 / math.Pi; angle <= demoThrowSector {
+			t.Fatalf("foul landed %.1f° off the centre line, inside the sector", angle)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package devices
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.bug.st/serial"
+
+	"PolyField/measurement"
+)
+
+// --- Device Identification ---
+const (
+	identifyReadSlice  = 200 * time.Millisecond
+	edmIdentifyTimeout = 1500 * time.Millisecond
+	windIdentifyWindow = 2500 * time.Millisecond
+	ConfidenceHigh     = "high"
+	ConfidenceLow      = "low"
+	DevTypeUnknown     = "unknown"
+)
+
+var AutoDetectBaudRates = []int{9600, 4800, 19200, 38400}
+var ErrNotIdentifiable = errors.New("device does not answer on its own")
+
+type PortSuggestion struct {
+	Port         string       `json:"port"`
+	DevType      string       `json:"devType"`
+	Confidence   string       `json:"confidence"`
+	Serial       SerialConfig `json:"serial"`
+	Profile      string       `json:"profile,omitempty"`
+	Response     string       `json:"response,omitempty"`
+	IsUSB        bool         `json:"isUsb"`
+	VID          string       `json:"vid,omitempty"`
+	PID          string       `json:"pid,omitempty"`
+	SerialNumber string       `json:"serialNumber,omitempty"`
+	Product      string       `json:"product,omitempty"`
+}
+
+// Candidate is one serial configuration to try for one device type.
+type Candidate struct {
+	DevType string
+	Profile string
+	Config  SerialConfig
+}
+
+// The EDM is sent a real read command and the wind gauge is listened to.
+// Scoreboards are display-only and cannot be told apart from a silent port.
+func (edmDriver) Identify(conn io.ReadWriter, cfg SerialConfig) (string, error) {
+	if _, err := conn.Write(measurement.ReadCommand); err != nil {
+		return "", err
+	}
+	line, err := readLineBefore(conn, cfg.ReadTerminator, time.Now().Add(edmIdentifyTimeout))
+	if err != nil {
+		return "", err
+	}
+	if _, err := measurement.ParseEDMResponse(line); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+func (windDriver) Identify(conn io.ReadWriter, cfg SerialConfig) (string, error) {
+	deadline := time.Now().Add(windIdentifyWindow)
+	for time.Now().Before(deadline) {
+		line, err := readLineBefore(conn, cfg.ReadTerminator, deadline)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := measurement.ParseWindResponse(line); ok {
+			return strings.TrimSpace(line), nil
+		}
+	}
+	return "", fmt.Errorf("no wind data")
+}
+func (scoreboardDriver) Identify(io.ReadWriter, SerialConfig) (string, error) {
+	return "", ErrNotIdentifiable
+}
+
+// readLineBefore reads a terminated line from a port opened with a short
+// read timeout, so that a silent device cannot block past the deadline.
+func readLineBefore(r io.Reader, term string, deadline time.Time) (string, error) {
+	if term == "" {
+		term = DefaultReadTerm
+	}
+	var line []byte
+	buf := make([]byte, 64)
+	for time.Now().Before(deadline) {
+		n, err := r.Read(buf)
+		line = append(line, buf[:n]...)
+		if i := bytes.Index(line, []byte(term)); i >= 0 {
+			return string(line[:i]), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("timed out")
+}
+
+// ProbePort tries every candidate on one port and returns the first device
+// that answers. A port where nothing answered may be a scoreboard, a dead
+// port or a device at settings not tried, so it is returned as unknown for
+// the officials to say what is attached.
+func ProbePort(portName string, candidates []Candidate) (*PortSuggestion, error) {
+	return probeCandidates(portName, candidates, func(mode *serial.Mode) (io.ReadWriteCloser, error) {
+		port, err := serial.Open(portName, mode)
+		if err != nil {
+			return nil, err
+		}
+		port.SetReadTimeout(identifyReadSlice)
+		port.ResetInputBuffer()
+		return port, nil
+	})
+}
+
+// probeCandidates opens the port afresh through open for each candidate.
+func probeCandidates(portName string, candidates []Candidate, open func(mode *serial.Mode) (io.ReadWriteCloser, error)) (*PortSuggestion, error) {
+	for _, c := range candidates {
+		driver, ok := Drivers[c.DevType]
+		if !ok {
+			continue
+		}
+		mode, err := c.Config.Mode()
+		if err != nil {
+			continue
+		}
+		port, err := open(mode)
+		if err != nil {
+			return nil, err
+		}
+		response, err := driver.Identify(port, c.Config)
+		port.Close()
+		if err == nil {
+			return &PortSuggestion{Port: portName, DevType: c.DevType, Confidence: ConfidenceHigh, Serial: c.Config, Profile: c.Profile, Response: response}, nil
+		}
+	}
+	return &PortSuggestion{Port: portName, DevType: DevTypeUnknown, Confidence: ConfidenceLow, Serial: DefaultSerialConfig()}, nil
+}
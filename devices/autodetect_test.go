@@ -0,0 +1,90 @@
+package devices
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"go.bug.st/serial"
+
+	"PolyField/measurement"
+)
+
+// scriptedPort answers writes through answer and otherwise sends stream;
+// with neither it reads as a port where nothing comes back.
+type scriptedPort struct {
+	answer func(written []byte) string
+	stream string
+	out    bytes.Buffer
+}
+
+func (p *scriptedPort) Read(b []byte) (int, error) {
+	if p.out.Len() == 0 {
+		if p.stream == "" {
+			return 0, io.EOF
+		}
+		p.out.WriteString(p.stream)
+	}
+	return p.out.Read(b)
+}
+func (p *scriptedPort) Write(b []byte) (int, error) {
+	if p.answer != nil {
+		p.out.WriteString(p.answer(b))
+	}
+	return len(b), nil
+}
+func (p *scriptedPort) Close() error { return nil }
+
+func edmAndWindCandidates() []Candidate {
+	var candidates []Candidate
+	for _, devType := range []string{"edm", "wind"} {
+		for _, baud := range AutoDetectBaudRates {
+			cfg := DefaultSerialConfig()
+			cfg.BaudRate = baud
+			candidates = append(candidates, Candidate{DevType: devType, Config: cfg})
+		}
+	}
+	return candidates
+}
+
+func TestProbeCandidates(t *testing.T) {
+	edm := func() *scriptedPort {
+		return &scriptedPort{answer: func(written []byte) string {
+			if bytes.Equal(written, measurement.ReadCommand) {
+				return "5000 0923000 0450000 0\r\n"
+			}
+			return ""
+		}}
+	}
+	for _, tc := range []struct {
+		name       string
+		ports      map[int]func() *scriptedPort
+		devType    string
+		confidence string
+		baud       int
+		response   string
+		opened     int
+	}{
+		{"edm", map[int]func() *scriptedPort{9600: edm}, "edm", ConfidenceHigh, 9600, "5000 0923000 0450000 0", 1},
+		{"wind at 19200 baud", map[int]func() *scriptedPort{19200: func() *scriptedPort { return &scriptedPort{stream: "WS,+1.23,M\r\n"} }}, "wind", ConfidenceHigh, 19200, "WS,+1.23,M", 7},
+		{"nothing answers", nil, DevTypeUnknown, ConfidenceLow, DefaultSerialConfig().BaudRate, "", 8},
+	} {
+		opened := 0
+		s, err := probeCandidates("COM3", edmAndWindCandidates(), func(mode *serial.Mode) (io.ReadWriteCloser, error) {
+			opened++
+			if port, ok := tc.ports[mode.BaudRate]; ok {
+				return port(), nil
+			}
+			return &scriptedPort{}, nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if s.DevType != tc.devType || s.Confidence != tc.confidence || s.Serial.BaudRate != tc.baud || s.Response != tc.response {
+			t.Errorf("%s: suggestion %+v", tc.name, s)
+		}
+		if opened != tc.opened {
+			t.Errorf("%s: port opened %d times, want %d", tc.name, opened, tc.opened)
+		}
+	}
+}
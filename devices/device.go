@@ -0,0 +1,62 @@
+// Package devices talks to field hardware: EDMs, wind gauges and
+// scoreboards over serial ports or TCP. Each Device serialises its own I/O,
+// tracks its health, and knows its type's protocol.
+package devices
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Device struct {
+	Conn            io.ReadWriteCloser
+	Type            string
+	ConnectionType  string
+	Address         string
+	Serial          *SerialConfig
+	ReadTerminator  string
+	WriteTerminator string
+	ctx             context.Context
+	cancel          context.CancelFunc
+	queue
+	healthState
+}
+
+// Start begins serving transactions on a freshly opened device.
+func (dev *Device) Start() {
+	dev.ctx, dev.cancel = context.WithCancel(context.Background())
+	dev.RecordSuccess(0)
+	dev.startQueue()
+}
+
+// Context is cancelled when the device is closed, which stops listeners.
+func (dev *Device) Context() context.Context {
+	if dev.ctx == nil {
+		return context.Background()
+	}
+	return dev.ctx
+}
+func (dev *Device) Close() error {
+	if dev.cancel != nil {
+		dev.cancel()
+	}
+	dev.stopQueue()
+	if dev.Conn == nil {
+		return nil
+	}
+	return dev.Conn.Close()
+}
+
+// TypeForID derives the device type from its name. The bare type names
+// ("edm", "wind", "scoreboard") keep working as before; further instances are
+// named "<type>-<label>", e.g. "edm-circle-A".
+func TypeForID(deviceID string) (string, error) {
+	for devType := range Drivers {
+		if deviceID == devType || strings.HasPrefix(deviceID, devType+"-") {
+			return devType, nil
+		}
+	}
+	return "", fmt.Errorf("cannot tell the device type of '%s': name it edm-…, wind-… or scoreboard-…", deviceID)
+}
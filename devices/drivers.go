@@ -0,0 +1,63 @@
+package devices
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// --- Device Type Drivers ---
+
+// Driver holds the behaviour that differs between device types.
+// Probe must be a no-op from the device's point of view: it must not
+// trigger a measurement or change what a scoreboard is displaying. It
+// reports a broken link as an error but never marks the device as seen;
+// only real replies do that, and a device not heard from ages into degraded
+// and then lost.
+// Identify is used by auto-detection on a freshly opened port and returns
+// the response that gave the device away.
+type Driver interface {
+	Probe(dev *Device) error
+	Identify(conn io.ReadWriter, cfg SerialConfig) (string, error)
+}
+
+var Drivers = map[string]Driver{
+	"edm":        edmDriver{},
+	"wind":       windDriver{},
+	"scoreboard": scoreboardDriver{},
+}
+
+type edmDriver struct{}
+type windDriver struct{}
+type scoreboardDriver struct{}
+
+// The EDM and scoreboard have no harmless command: a read triggers a
+// measurement and any frame changes the display. Probing therefore only
+// checks the link, and their status follows their measurements and writes.
+func (edmDriver) Probe(dev *Device) error        { return probeLink(dev) }
+func (scoreboardDriver) Probe(dev *Device) error { return probeLink(dev) }
+
+// The wind gauge streams continuously; the listener records each reading,
+// so the probe only has to notice when the stream has stopped.
+func (windDriver) Probe(dev *Device) error {
+	if idle := time.Since(dev.LastSeen()); idle > DegradedAfter {
+		return fmt.Errorf("no wind data for %s", idle.Round(time.Second))
+	}
+	return nil
+}
+
+// probeLink checks that the underlying connection is still usable without
+// writing to it. Serial ports are asked for their modem status bits, which
+// fails once a USB adapter is unplugged. A TCP connection is left alone:
+// reading from it could swallow the start of a reply.
+func probeLink(dev *Device) error {
+	return dev.TryTransact(func(c io.ReadWriteCloser) error {
+		if port, ok := c.(serial.Port); ok {
+			_, err := port.GetModemStatusBits()
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package devices
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"time"
+
+	"PolyField/measurement"
+)
+
+// --- EDM ---
+const EDMReadTimeout = 10 * time.Second
+
+// ReadEDM triggers one reading on conn, which must belong to dev's current
+// transaction.
+func (dev *Device) ReadEDM(conn io.ReadWriteCloser) (*measurement.ParsedReading, error) {
+	if _, err := conn.Write(measurement.ReadCommand); err != nil {
+		return nil, err
+	}
+	if dev.ConnectionType == "network" {
+		if nc, ok := conn.(net.Conn); ok {
+			nc.SetReadDeadline(time.Now().Add(EDMReadTimeout))
+			defer nc.SetReadDeadline(time.Time{})
+		}
+	}
+	r := bufio.NewReader(conn)
+	resp, err := ReadTerminated(r, dev.ReadTerminator)
+	if err != nil {
+		return nil, err
+	}
+	return measurement.ParseEDMResponse(resp)
+}
+
+// ReliableReading takes a consistent pair of readings. Both reads form one
+// transaction so that no other command can reach the EDM between them.
+func (dev *Device) ReliableReading() (*measurement.AveragedReading, error) {
+	var reading *measurement.AveragedReading
+	err := dev.Transact(func(conn io.ReadWriteCloser) error {
+		var err error
+		reading, err = measurement.ReadPair(func() (*measurement.ParsedReading, error) {
+			start := time.Now()
+			r, err := dev.ReadEDM(conn)
+			if err != nil {
+				dev.RecordFailure(err)
+				return nil, err
+			}
+			dev.RecordSuccess(time.Since(start))
+			return r, nil
+		})
+		return err
+	})
+	return reading, err
+}
@@ -0,0 +1,88 @@
+package devices
+
+import (
+	"sync"
+	"time"
+)
+
+// --- Device Health ---
+const (
+	DegradedAfter   = 15 * time.Second
+	LostAfter       = 45 * time.Second
+	lostAfterErrors = 3
+	StatusConnected = "connected"
+	StatusDegraded  = "degraded"
+	StatusLost      = "lost"
+)
+
+type Health struct {
+	DeviceID          string    `json:"deviceId"`
+	DevType           string    `json:"devType"`
+	Status            string    `json:"status"`
+	ConnectionType    string    `json:"connectionType"`
+	Address           string    `json:"address"`
+	LastSeen          time.Time `json:"lastSeen"`
+	LatencyMs         float64   `json:"latencyMs"`
+	ErrorCount        int       `json:"errorCount"`
+	ConsecutiveErrors int       `json:"consecutiveErrors"`
+	LastError         string    `json:"lastError,omitempty"`
+}
+
+// healthState is embedded in Device and guarded by its own mutex so that
+// listeners and measurements can report activity without any App lock.
+type healthState struct {
+	healthMux         sync.Mutex
+	lastSeen          time.Time
+	latency           time.Duration
+	errorCount        int
+	consecutiveErrors int
+	lastError         string
+}
+
+func (h *healthState) RecordSuccess(latency time.Duration) {
+	h.healthMux.Lock()
+	defer h.healthMux.Unlock()
+	h.lastSeen = time.Now()
+	if latency > 0 {
+		h.latency = latency
+	}
+	h.consecutiveErrors = 0
+}
+func (h *healthState) RecordFailure(err error) {
+	h.healthMux.Lock()
+	defer h.healthMux.Unlock()
+	h.errorCount++
+	h.consecutiveErrors++
+	h.lastError = err.Error()
+}
+func (h *healthState) LastSeen() time.Time {
+	h.healthMux.Lock()
+	defer h.healthMux.Unlock()
+	return h.lastSeen
+}
+func (dev *Device) Health(deviceID string, now time.Time) Health {
+	h := &dev.healthState
+	h.healthMux.Lock()
+	defer h.healthMux.Unlock()
+	health := Health{
+		DeviceID:          deviceID,
+		DevType:           dev.Type,
+		ConnectionType:    dev.ConnectionType,
+		Address:           dev.Address,
+		LastSeen:          h.lastSeen,
+		LatencyMs:         float64(h.latency.Microseconds()) / 1000.0,
+		ErrorCount:        h.errorCount,
+		ConsecutiveErrors: h.consecutiveErrors,
+		LastError:         h.lastError,
+	}
+	idle := now.Sub(h.lastSeen)
+	switch {
+	case h.consecutiveErrors >= lostAfterErrors || idle > LostAfter:
+		health.Status = StatusLost
+	case h.consecutiveErrors > 0 || idle > DegradedAfter:
+		health.Status = StatusDegraded
+	default:
+		health.Status = StatusConnected
+	}
+	return health
+}
@@ -0,0 +1,82 @@
+package devices
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// --- Per-Device Command Queue ---
+const queueDepth = 16
+
+var (
+	ErrClosed = errors.New("device closed")
+	ErrBusy   = errors.New("device busy")
+)
+
+// tx is one command/response exchange with a device. Everything a
+// transaction writes and reads happens before the next one starts, so two
+// callers can never interleave bytes on the same link.
+type tx struct {
+	fn   func(conn io.ReadWriteCloser) error
+	done chan error
+}
+
+// queue is embedded in Device. Transactions run in FIFO order on a single
+// worker goroutine per device.
+type queue struct {
+	txs      chan *tx
+	stop     chan struct{}
+	inFlight atomic.Bool
+}
+
+func (dev *Device) startQueue() {
+	dev.txs = make(chan *tx, queueDepth)
+	dev.stop = make(chan struct{})
+	go dev.runQueue()
+}
+func (dev *Device) stopQueue() {
+	if dev.stop != nil {
+		close(dev.stop)
+	}
+}
+func (dev *Device) runQueue() {
+	for {
+		select {
+		case <-dev.stop:
+			return
+		case t := <-dev.txs:
+			dev.inFlight.Store(true)
+			t.done <- t.fn(dev.Conn)
+			dev.inFlight.Store(false)
+		}
+	}
+}
+
+// Transact queues fn behind any earlier transactions and waits for it to run.
+func (dev *Device) Transact(fn func(conn io.ReadWriteCloser) error) error {
+	if dev.txs == nil {
+		return ErrClosed
+	}
+	t := &tx{fn: fn, done: make(chan error, 1)}
+	select {
+	case dev.txs <- t:
+	case <-dev.stop:
+		return ErrClosed
+	}
+	select {
+	case err := <-t.done:
+		return err
+	case <-dev.stop:
+		return ErrClosed
+	}
+}
+
+// TryTransact runs fn only if the device is idle, for background work such as
+// health probes that should never delay a measurement.
+func (dev *Device) TryTransact(fn func(conn io.ReadWriteCloser) error) error {
+	if dev.inFlight.Load() || len(dev.txs) > 0 {
+		return ErrBusy
+	}
+	return dev.Transact(fn)
+}
@@ -0,0 +1,215 @@
+package devices
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// --- Scoreboard Protocol Drivers ---
+const (
+	ScoreboardGenericASCII = "generic"
+	ScoreboardALGE         = "alge"
+	ScoreboardDaktronics   = "daktronics"
+	algeLineWidth          = 24
+)
+
+// ScoreboardMessage is what the officials want shown. Drivers lay out the
+// structured fields for their display; Lines is free text and, when set on
+// its own, is shown as-is (one entry per display line).
+type ScoreboardMessage struct {
+	Bib     string   `json:"bib,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Attempt int      `json:"attempt,omitempty"`
+	Mark    string   `json:"mark,omitempty"`
+	Wind    string   `json:"wind,omitempty"`
+	Rank    int      `json:"rank,omitempty"`
+	Flags   []string `json:"flags,omitempty"`
+	Lines   []string `json:"lines,omitempty"`
+}
+
+// ScoreboardDriver turns messages into the bytes a particular display model
+// expects. Brightness takes a level from 0 to 100.
+type ScoreboardDriver interface {
+	Format(msg ScoreboardMessage, terminator string) []byte
+	Brightness(level int, terminator string) ([]byte, error)
+	Clear(terminator string) []byte
+}
+
+var ScoreboardDrivers = map[string]ScoreboardDriver{
+	ScoreboardGenericASCII: genericScoreboard{},
+	ScoreboardALGE:         algeScoreboard{},
+	ScoreboardDaktronics:   daktronicsScoreboard{},
+}
+
+// displayLines is the common layout: free text wins, otherwise one line for
+// the athlete, one for the mark and one for wind, rank and any PB/SB/record
+// flags, skipping blanks.
+func (msg ScoreboardMessage) displayLines() []string {
+	if len(msg.Lines) > 0 {
+		return msg.Lines
+	}
+	var lines []string
+	athlete := strings.TrimSpace(strings.Join([]string{msg.Bib, msg.Name}, " "))
+	if msg.Attempt > 0 {
+		athlete = strings.TrimSpace(fmt.Sprintf("%s  #%d", athlete, msg.Attempt))
+	}
+	if athlete != "" {
+		lines = append(lines, athlete)
+	}
+	if msg.Mark != "" {
+		lines = append(lines, msg.Mark)
+	}
+	var extra []string
+	if msg.Wind != "" {
+		extra = append(extra, msg.Wind)
+	}
+	if msg.Rank > 0 {
+		extra = append(extra, fmt.Sprintf("R%d", msg.Rank))
+	}
+	extra = append(extra, msg.Flags...)
+	if len(extra) > 0 {
+		lines = append(lines, strings.Join(extra, "  "))
+	}
+	return lines
+}
+
+// genericScoreboard writes plain text lines, which is what PolyField has
+// always sent. Single-line displays therefore still just show the value.
+type genericScoreboard struct{}
+
+func (genericScoreboard) Format(msg ScoreboardMessage, terminator string) []byte {
+	var b strings.Builder
+	for _, line := range msg.displayLines() {
+		b.WriteString(line)
+		b.WriteString(terminator)
+	}
+	return []byte(b.String())
+}
+func (genericScoreboard) Brightness(int, string) ([]byte, error) {
+	return nil, fmt.Errorf("generic ASCII displays have no brightness command")
+}
+func (genericScoreboard) Clear(terminator string) []byte { return []byte(terminator) }
+
+// algeScoreboard follows the ALGE GAZ/D-Line field-event layout: fixed-width
+// right-aligned columns, one CR-terminated record per display line, with the
+// line number in the first column for multi-line boards.
+type algeScoreboard struct{}
+
+func (algeScoreboard) Format(msg ScoreboardMessage, _ string) []byte {
+	var b strings.Builder
+	if len(msg.Lines) > 0 {
+		for i, line := range msg.Lines {
+			fmt.Fprintf(&b, "%d%*s\r", i+1, algeLineWidth, truncate(line, algeLineWidth))
+		}
+		return []byte(b.String())
+	}
+	// Line 1: bib, attempt, rank and flags; line 2: mark and wind.
+	rank := strings.Join(msg.Flags, " ")
+	if msg.Rank > 0 {
+		rank = strings.TrimSpace(strconv.Itoa(msg.Rank) + " " + rank)
+	}
+	attempt := ""
+	if msg.Attempt > 0 {
+		attempt = strconv.Itoa(msg.Attempt)
+	}
+	fmt.Fprintf(&b, "1%6s%4s%14s\r", truncate(msg.Bib, 6), attempt, truncate(rank, 14))
+	fmt.Fprintf(&b, "2%10s%14s\r", truncate(msg.Mark, 10), truncate(msg.Wind, 14))
+	return []byte(b.String())
+}
+func (algeScoreboard) Brightness(level int, _ string) ([]byte, error) {
+	if level < 0 || level > 100 {
+		return nil, fmt.Errorf("brightness must be 0-100, got %d", level)
+	}
+	// ALGE boards take nine brightness steps.
+	return []byte(fmt.Sprintf("B%d\r", 1+level*8/100)), nil
+}
+func (algeScoreboard) Clear(_ string) []byte { return []byte("C\r") }
+
+// daktronicsScoreboard wraps each line in an STX/ETX frame addressed to a
+// display zone, followed by a two-digit hex checksum and EOT.
+type daktronicsScoreboard struct{}
+
+const (
+	asciiSTX = 0x02
+	asciiETX = 0x03
+	asciiEOT = 0x04
+)
+
+func daktronicsFrame(body string) []byte {
+	frame := append([]byte{asciiSTX}, body...)
+	frame = append(frame, asciiETX)
+	var sum byte
+	for _, c := range frame[1:] {
+		sum += c
+	}
+	frame = append(frame, fmt.Sprintf("%02X", sum)...)
+	return append(frame, asciiEOT)
+}
+func (daktronicsScoreboard) Format(msg ScoreboardMessage, _ string) []byte {
+	var out []byte
+	for i, line := range msg.displayLines() {
+		out = append(out, daktronicsFrame(fmt.Sprintf("Z%02d%s", i+1, line))...)
+	}
+	return out
+}
+func (daktronicsScoreboard) Brightness(level int, _ string) ([]byte, error) {
+	if level < 0 || level > 100 {
+		return nil, fmt.Errorf("brightness must be 0-100, got %d", level)
+	}
+	return daktronicsFrame(fmt.Sprintf("BR%03d", level)), nil
+}
+func (daktronicsScoreboard) Clear(_ string) []byte { return daktronicsFrame("CLR") }
+
+// truncate keeps the first n characters of s; fmt widths count characters
+// too, so names with accents still fill their column exactly.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) > n {
+		return string([]rune(s)[:n])
+	}
+	return s
+}
+
+// ScoreboardDriverNames lists the registered protocols.
+func ScoreboardDriverNames() []string {
+	names := make([]string, 0, len(ScoreboardDrivers))
+	for name := range ScoreboardDrivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ScoreboardDriverFor returns the named driver, or generic ASCII if the name
+// is empty or unknown.
+func ScoreboardDriverFor(name string) ScoreboardDriver {
+	if driver, ok := ScoreboardDrivers[name]; ok {
+		return driver
+	}
+	return ScoreboardDrivers[ScoreboardGenericASCII]
+}
+
+// WriteTerm is the terminator for lines written to the device.
+func (dev *Device) WriteTerm() string {
+	if dev.WriteTerminator == "" {
+		return DefaultWriteTerm
+	}
+	return dev.WriteTerminator
+}
+
+// Send writes payload as one transaction.
+func (dev *Device) Send(payload []byte) error {
+	return dev.Transact(func(conn io.ReadWriteCloser) error {
+		start := time.Now()
+		if _, err := conn.Write(payload); err != nil {
+			dev.RecordFailure(err)
+			return fmt.Errorf("failed to write to %s: %w", dev.Type, err)
+		}
+		dev.RecordSuccess(time.Since(start))
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package devices
+
+import "testing"
+
+func TestScoreboardFormat(t *testing.T) {
+	result := ScoreboardMessage{Bib: "101", Name: "Ana Ruiz", Attempt: 2, Mark: "12.34 m", Wind: "+0.4 m/s", Rank: 1, Flags: []string{"PB"}}
+	for _, tc := range []struct {
+		name   string
+		driver string
+		msg    ScoreboardMessage
+		want   string
+	}{
+		{"generic result", ScoreboardGenericASCII, result, "101 Ana Ruiz  #2\r\n12.34 m\r\n+0.4 m/s  R1  PB\r\n"},
+		{"generic lines", ScoreboardGenericASCII, ScoreboardMessage{Lines: []string{"BREAK"}}, "BREAK\r\n"},
+		{"alge result", ScoreboardALGE, ScoreboardMessage{Bib: "101", Attempt: 2, Mark: "12.34", Wind: "+0.4", Rank: 1, Flags: []string{"PB"}},
+			"1   101   2          1 PB\r" + "2     12.34          +0.4\r"},
+		{"alge result without rank", ScoreboardALGE, ScoreboardMessage{Bib: "7", Mark: "FOUL"},
+			"1     7                  \r" + "2      FOUL              \r"},
+		{"alge bib truncated", ScoreboardALGE, ScoreboardMessage{Bib: "1234567", Mark: "12.345678901"},
+			"1123456                  \r" + "212.3456789              \r"},
+		{"alge lines", ScoreboardALGE, ScoreboardMessage{Lines: []string{"BREAK", "Åsa Lindström-Johansson, Malmö"}},
+			"1                   BREAK\r" + "2Åsa Lindström-Johansson,\r"},
+		{"daktronics result", ScoreboardDaktronics, ScoreboardMessage{Bib: "101", Name: "Ana Ruiz", Attempt: 2, Mark: "12.34 m", Wind: "+0.4 m/s", Rank: 1, Flags: []string{"PB"}},
+			"\x02Z01101 Ana Ruiz  #2\x03DF\x04" + "\x02Z0212.34 m\x0344\x04" + "\x02Z03+0.4 m/s  R1  PB\x0341\x04"},
+	} {
+		if got := string(ScoreboardDrivers[tc.driver].Format(tc.msg, "\r\n")); got != tc.want {
+			t.Errorf("%s: Format = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScoreboardBrightnessAndClear(t *testing.T) {
+	for _, tc := range []struct {
+		driver string
+		level  int
+		want   string
+	}{
+		{ScoreboardALGE, 0, "B1\r"},
+		{ScoreboardALGE, 12, "B1\r"},
+		{ScoreboardALGE, 13, "B2\r"},
+		{ScoreboardALGE, 50, "B5\r"},
+		{ScoreboardALGE, 100, "B9\r"},
+		{ScoreboardDaktronics, 0, "\x02BR000\x0327\x04"},
+		{ScoreboardDaktronics, 50, "\x02BR050\x032C\x04"},
+		{ScoreboardDaktronics, 100, "\x02BR100\x0328\x04"},
+	} {
+		got, err := ScoreboardDrivers[tc.driver].Brightness(tc.level, "\r\n")
+		if err != nil || string(got) != tc.want {
+			t.Errorf("%s Brightness(%d) = %q, %v; want %q", tc.driver, tc.level, got, err, tc.want)
+		}
+	}
+	for _, driver := range ScoreboardDriverNames() {
+		for _, level := range []int{-1, 101} {
+			if _, err := ScoreboardDrivers[driver].Brightness(level, "\r\n"); err == nil {
+				t.Errorf("%s accepted brightness %d", driver, level)
+			}
+		}
+	}
+	for driver, want := range map[string]string{
+		ScoreboardGenericASCII: "\r\n",
+		ScoreboardALGE:         "C\r",
+		ScoreboardDaktronics:   "\x02CLR\x03E4\x04",
+	} {
+		if got := string(ScoreboardDrivers[driver].Clear("\r\n")); got != want {
+			t.Errorf("%s Clear = %q, want %q", driver, got, want)
+		}
+	}
+}
+
+func TestTruncateKeepsWholeCharacters(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"Müller", 2, "Mü"},
+		{"Müller", 6, "Müller"},
+		{"Ruiz", 10, "Ruiz"},
+		{"ÅÖ", 1, "Å"},
+	} {
+		if got := truncate(tc.s, tc.n); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.s, tc.n, got, tc.want)
+		}
+	}
+}
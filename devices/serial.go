@@ -0,0 +1,196 @@
+package devices
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// --- Serial Configuration & Framing ---
+const (
+	FlowControlNone   = "none"
+	FlowControlRTSCTS = "rtscts"
+	ctsWaitTimeout    = 2 * time.Second
+	ctsPollInterval   = 10 * time.Millisecond
+	DefaultReadTerm   = "\n"
+	DefaultWriteTerm  = "\r\n"
+)
+
+type SerialConfig struct {
+	BaudRate        int    `json:"baudRate"`
+	DataBits        int    `json:"dataBits"`
+	Parity          string `json:"parity"`
+	StopBits        string `json:"stopBits"`
+	FlowControl     string `json:"flowControl"`
+	ReadTerminator  string `json:"readTerminator"`
+	WriteTerminator string `json:"writeTerminator"`
+}
+
+func DefaultSerialConfig() SerialConfig {
+	return SerialConfig{
+		BaudRate:        9600,
+		DataBits:        8,
+		Parity:          "none",
+		StopBits:        "1",
+		FlowControl:     FlowControlNone,
+		ReadTerminator:  DefaultReadTerm,
+		WriteTerminator: DefaultWriteTerm,
+	}
+}
+
+// WithDefaults fills any zero-valued field so partially specified configs
+// from the UI behave like the historic 9600 8N1 connection.
+func (c SerialConfig) WithDefaults() SerialConfig {
+	d := DefaultSerialConfig()
+	if c.BaudRate == 0 {
+		c.BaudRate = d.BaudRate
+	}
+	if c.DataBits == 0 {
+		c.DataBits = d.DataBits
+	}
+	if c.Parity == "" {
+		c.Parity = d.Parity
+	}
+	if c.StopBits == "" {
+		c.StopBits = d.StopBits
+	}
+	if c.FlowControl == "" {
+		c.FlowControl = d.FlowControl
+	}
+	if c.ReadTerminator == "" {
+		c.ReadTerminator = d.ReadTerminator
+	}
+	if c.WriteTerminator == "" {
+		c.WriteTerminator = d.WriteTerminator
+	}
+	return c
+}
+func (c SerialConfig) Mode() (*serial.Mode, error) {
+	if c.BaudRate <= 0 {
+		return nil, fmt.Errorf("invalid baud rate %d", c.BaudRate)
+	}
+	if c.DataBits < 5 || c.DataBits > 8 {
+		return nil, fmt.Errorf("invalid data bits %d (must be 5-8)", c.DataBits)
+	}
+	mode := &serial.Mode{BaudRate: c.BaudRate, DataBits: c.DataBits}
+	switch strings.ToLower(c.Parity) {
+	case "none", "n":
+		mode.Parity = serial.NoParity
+	case "odd", "o":
+		mode.Parity = serial.OddParity
+	case "even", "e":
+		mode.Parity = serial.EvenParity
+	case "mark", "m":
+		mode.Parity = serial.MarkParity
+	case "space", "s":
+		mode.Parity = serial.SpaceParity
+	default:
+		return nil, fmt.Errorf("invalid parity '%s'", c.Parity)
+	}
+	switch c.StopBits {
+	case "1":
+		mode.StopBits = serial.OneStopBit
+	case "1.5":
+		mode.StopBits = serial.OnePointFiveStopBits
+	case "2":
+		mode.StopBits = serial.TwoStopBits
+	default:
+		return nil, fmt.Errorf("invalid stop bits '%s'", c.StopBits)
+	}
+	switch c.FlowControl {
+	case FlowControlNone:
+	case FlowControlRTSCTS:
+		mode.InitialStatusBits = &serial.ModemOutputBits{RTS: true, DTR: true}
+	default:
+		return nil, fmt.Errorf("unsupported flow control '%s'", c.FlowControl)
+	}
+	return mode, nil
+}
+
+// OpenSerial opens portName with cfg, which must already have its defaults
+// filled in.
+func OpenSerial(portName string, cfg SerialConfig) (io.ReadWriteCloser, error) {
+	mode, err := cfg.Mode()
+	if err != nil {
+		return nil, fmt.Errorf("invalid serial settings: %w", err)
+	}
+	port, err := serial.Open(portName, mode)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.FlowControl == FlowControlRTSCTS {
+		return &ctsGatedPort{Port: port}, nil
+	}
+	return port, nil
+}
+
+// ctsGatedPort implements RTS/CTS handshaking on top of go.bug.st/serial,
+// which has no native hardware flow control: RTS is held asserted and each
+// write waits for the device to raise CTS.
+type ctsGatedPort struct {
+	serial.Port
+}
+
+func (p *ctsGatedPort) Write(b []byte) (int, error) {
+	deadline := time.Now().Add(ctsWaitTimeout)
+	for {
+		bits, err := p.GetModemStatusBits()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read CTS: %w", err)
+		}
+		if bits.CTS {
+			break
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("timed out waiting for CTS")
+		}
+		time.Sleep(ctsPollInterval)
+	}
+	return p.Port.Write(b)
+}
+
+// ReadTerminated reads up to and including term, returning the line with
+// the terminator stripped.
+func ReadTerminated(r *bufio.Reader, term string) (string, error) {
+	if term == "" {
+		term = DefaultReadTerm
+	}
+	last := term[len(term)-1]
+	var line []byte
+	for {
+		chunk, err := r.ReadBytes(last)
+		line = append(line, chunk...)
+		if err != nil {
+			return string(line), err
+		}
+		if bytes.HasSuffix(line, []byte(term)) {
+			return string(line[:len(line)-len(term)]), nil
+		}
+	}
+}
+
+// ScanTerminated is a bufio.SplitFunc equivalent of ReadTerminated for
+// streaming devices such as the wind gauge.
+func ScanTerminated(term string) bufio.SplitFunc {
+	if term == "" || term == DefaultReadTerm {
+		return bufio.ScanLines
+	}
+	sep := []byte(term)
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
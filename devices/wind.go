@@ -0,0 +1,37 @@
+package devices
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"PolyField/measurement"
+)
+
+// ListenWind reads the gauge's stream until ctx is cancelled or the stream
+// ends, passing each speed to onReading. A stream that ends on its own is
+// recorded as a failure and returned as an error.
+func (dev *Device) ListenWind(ctx context.Context, onReading func(float64)) error {
+	scanner := bufio.NewScanner(dev.Conn)
+	scanner.Split(ScanTerminated(dev.ReadTerminator))
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if val, ok := measurement.ParseWindResponse(scanner.Text()); ok {
+			dev.RecordSuccess(0)
+			onReading(val)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	err = fmt.Errorf("wind stream ended: %w", err)
+	dev.RecordFailure(err)
+	return err
+}
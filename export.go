@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"PolyField/competition"
+	"PolyField/export"
+)
+
+// --- Results Export ---
+// For meetings without a results server, an event's series and standings
+// can be written to a folder as CSV, a PDF result sheet and a Lynx-style
+// (.lif) file for meet-management software.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func (a *App) ListExportFormats() []string {
+	formats := make([]string, 0, len(export.Formats))
+	for name := range export.Formats {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ExportEventResults writes the event in each format (all when none are
+// given) to folder, creating it if needed, and returns the files written.
+func (a *App) ExportEventResults(eventID, folder string, formats []string) ([]string, error) {
+	if len(formats) == 0 {
+		formats = a.ListExportFormats()
+	}
+	for _, f := range formats {
+		if _, ok := export.Formats[f]; !ok {
+			return nil, fmt.Errorf("unknown export format '%s'", f)
+		}
+	}
+	sheet, err := a.resultSheet(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range formats {
+		format := export.Formats[f]
+		path := filepath.Join(folder, unsafeFileChars.ReplaceAllString(eventID, "_")+format.Ext)
+		if err := writeSheet(path, sheet, format.Write); err != nil {
+			return paths, fmt.Errorf("could not write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+func writeSheet(path string, sheet export.Sheet, write func(w io.Writer, s export.Sheet) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, sheet); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// resultSheet lays out a vertical jump by bar height, any other event by
+// round.
+func (a *App) resultSheet(eventID string) (export.Sheet, error) {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	if ve, ok := a.verticals[eventID]; ok {
+		return verticalSheet(ve), nil
+	}
+	he, ok := a.horizontals[eventID]
+	if !ok {
+		return export.Sheet{}, fmt.Errorf("no results for event '%s'", eventID)
+	}
+	sheet := export.Sheet{EventID: eventID, EventName: he.event.Name}
+	athletes := athletesByBib(he.event)
+	series := make(map[string][]Performance, len(he.entries))
+	rounds := 0
+	for _, e := range he.entries {
+		series[e.Bib] = e.Series
+		rounds = max(rounds, len(e.Series))
+	}
+	for i := 1; i <= rounds; i++ {
+		sheet.Columns = append(sheet.Columns, strconv.Itoa(i))
+	}
+	for _, st := range a.standings(eventID) {
+		row := export.Row{Rank: st.Rank, Bib: st.Bib, Name: athletes[st.Bib].Name, Club: athletes[st.Bib].Club, Best: st.Best, Status: st.Status}
+		for _, p := range series[st.Bib] {
+			row.Marks = append(row.Marks, p.Code())
+			if st.Best != "" && p.Mark == st.Best && row.Flags == nil {
+				row.Flags = p.Flags
+			}
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	return sheet, nil
+}
+
+// verticalSheet must be called with competitionMux held.
+func verticalSheet(ve *verticalEvent) export.Sheet {
+	sheet := export.Sheet{EventID: ve.event.ID, EventName: ve.event.Name}
+	heights := ve.jump.Heights()
+	for _, h := range heights {
+		sheet.Columns = append(sheet.Columns, competition.FormatHeight(h))
+	}
+	athletes := athletesByBib(ve.event)
+	for _, st := range ve.jump.Ranking() {
+		row := export.Row{Rank: st.Rank, Bib: st.Bib, Name: athletes[st.Bib].Name, Club: athletes[st.Bib].Club, Best: st.Best, Status: st.Status}
+		attempts := make(map[string]string)
+		records, _ := ve.jump.Records(st.Bib)
+		for _, r := range records {
+			if !r.JumpOff {
+				attempts[r.Height] = r.Attempts
+			}
+		}
+		for _, column := range sheet.Columns {
+			row.Marks = append(row.Marks, attempts[column])
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	return sheet
+}
+func athletesByBib(event Event) map[string]Athlete {
+	athletes := make(map[string]Athlete, len(event.Athletes))
+	for _, ath := range event.Athletes {
+		athletes[ath.Bib] = ath
+	}
+	return athletes
+}
@@ -0,0 +1,27 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// WriteCSV writes one row per athlete under a header row.
+func WriteCSV(w io.Writer, s Sheet) error {
+	cw := csv.NewWriter(w)
+	header := append([]string{"Place", "Bib", "Name", "Club"}, s.Columns...)
+	cw.Write(append(header, "Best", "Status", "Flags"))
+	for _, r := range s.Rows {
+		record := []string{r.place(), r.Bib, r.Name, r.Club}
+		for i := range s.Columns {
+			mark := ""
+			if i < len(r.Marks) {
+				mark = r.Marks[i]
+			}
+			record = append(record, mark)
+		}
+		cw.Write(append(record, r.Best, r.Status, strings.Join(r.Flags, " ")))
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testSheet = Sheet{
+	EventID:   "sp-m",
+	EventName: "Shot Put Men",
+	Columns:   []string{"1", "2", "3"},
+	Rows: []Row{
+		{Rank: 1, Bib: "7", Name: "Sam O'Neil", Club: "KACPH", Marks: []string{"14.10", "X", "14.52"}, Best: "14.52", Flags: []string{"PB"}},
+		{Rank: 2, Bib: "3", Name: "Ana (Jr) Lopez", Marks: []string{"13.00", "r"}, Best: "13.00", Status: "r"},
+		{Bib: "9", Name: "Zoë", Status: "DNS"},
+	},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCSV(&b, testSheet); err != nil {
+		t.Fatal(err)
+	}
+	want := "Place,Bib,Name,Club,1,2,3,Best,Status,Flags\n" +
+		"1,7,Sam O'Neil,KACPH,14.10,X,14.52,14.52,,PB\n" +
+		"2,3,Ana (Jr) Lopez,,13.00,r,,13.00,r,\n" +
+		"DNS,9,Zoë,,,,,,DNS,\n"
+	if b.String() != want {
+		t.Fatalf("CSV\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteLynx(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteLynx(&b, testSheet); err != nil {
+		t.Fatal(err)
+	}
+	want := "sp-m,1,1,Shot Put Men\n" +
+		"1,7,,O'Neil,Sam,KACPH,14.52 PB\n" +
+		"2,3,,Lopez,Ana (Jr),,13.00 r\n" +
+		"DNS,9,,Zoë,,,\n"
+	if b.String() != want {
+		t.Fatalf("LIF\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	sheet := testSheet
+	for i := 0; i < 120; i++ {
+		sheet.Rows = append(sheet.Rows, Row{Bib: fmt.Sprint(100 + i)})
+	}
+	var b bytes.Buffer
+	if err := WritePDF(&b, sheet); err != nil {
+		t.Fatal(err)
+	}
+	pdf := b.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("not a PDF file")
+	}
+	if !strings.Contains(pdf, "/Count 3") {
+		t.Fatal("123 rows should take three pages")
+	}
+	if !strings.Contains(pdf, `Ana \(Jr\) Lopez`) || !strings.Contains(pdf, "Zo?") {
+		t.Fatal("text not escaped for the built-in font")
+	}
+	// Every cross-reference entry must point at its object.
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(pdf)
+	xref, _ := strconv.Atoi(m[1])
+	entries := strings.Split(pdf[xref:], "\n")[3:]
+	for i, e := range entries {
+		if !strings.HasSuffix(e, " n ") {
+			break
+		}
+		off, _ := strconv.Atoi(e[:10])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(pdf[off:], want) {
+			t.Fatalf("xref entry %d points at %q", i+1, pdf[off:off+10])
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// WriteLynx writes a Lynx-style (.lif) result file as read by meet managers
+// such as Hy-Tek: a header line with the event number, round, heat and name,
+// then one line per athlete with place, ID, lane, last name, first name,
+// affiliation and mark. Field events have no lanes, and round and heat are
+// always 1.
+func WriteLynx(w io.Writer, s Sheet) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{s.EventID, "1", "1", s.EventName})
+	for _, r := range s.Rows {
+		first, last := splitName(r.Name)
+		cw.Write([]string{r.place(), r.Bib, "", last, first, r.Club, r.result()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// --- PDF Result Sheet ---
+// A plain A4 sheet in the built-in Courier font, so nothing is embedded and
+// columns line up. The font shrinks to fit wide sheets (many bar heights).
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfMaxFontSize  = 9.0
+	pdfCourierWidth = 0.6 // of the font size, for every character
+	pdfLineSpacing  = 1.35
+)
+
+// WritePDF writes the sheet as a PDF document of one or more pages.
+func WritePDF(w io.Writer, s Sheet) error {
+	lines := sheetLines(s)
+	widest := 1
+	for _, l := range lines {
+		widest = max(widest, len(l))
+	}
+	size := min(pdfMaxFontSize, float64(pdfPageWidth-2*pdfMargin)/(float64(widest)*pdfCourierWidth))
+	leading := size * pdfLineSpacing
+	perPage := int(float64(pdfPageHeight-2*pdfMargin) / leading)
+
+	var pages [][]string
+	for len(lines) > perPage {
+		pages = append(pages, lines[:perPage])
+		lines = lines[perPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objects 1-3 are the catalog, page tree and font; each page then takes
+	// a content stream and a page object.
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	for i, page := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %.2f Tf %.2f TL %d %d Td\n", size, leading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, l := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(l))
+		}
+		content.WriteString("ET")
+		objects = append(objects,
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 4+2*i))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// sheetLines lays the sheet out as fixed-width text.
+func sheetLines(s Sheet) []string {
+	markWidth := 6
+	for _, c := range s.Columns {
+		markWidth = max(markWidth, len(c)+1)
+	}
+	row := func(place, bib, name, club string, marks []string, result string) string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%-5s %-6s %-22s %-14s", clip(place, 5), clip(bib, 6), clip(name, 22), clip(club, 14))
+		for i := range s.Columns {
+			mark := ""
+			if i < len(marks) {
+				mark = marks[i]
+			}
+			fmt.Fprintf(&b, " %-*s", markWidth, clip(mark, markWidth))
+		}
+		return strings.TrimRight(b.String()+" "+ascii(result), " ")
+	}
+	title := s.EventName
+	if title == "" {
+		title = s.EventID
+	}
+	lines := []string{ascii(title), "", row("Place", "Bib", "Name", "Club", s.Columns, "Result")}
+	for _, r := range s.Rows {
+		lines = append(lines, row(r.place(), r.Bib, r.Name, r.Club, r.Marks, r.result()))
+	}
+	return lines
+}
+
+// clip fits s to n characters once made ASCII.
+func clip(s string, n int) string {
+	s = ascii(s)
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// ascii replaces what the built-in font cannot show with '?', keeping one
+// character per rune so columns stay aligned.
+func ascii(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 32 || r > 126 {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// pdfEscape makes ASCII text safe inside a PDF string.
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
@@ -0,0 +1,66 @@
+// Package export writes an event's results to files for meetings run without
+// a results server: CSV, a printable PDF sheet and a Lynx-style interchange
+// file.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sheet is an event's results in finishing order. Columns heads the attempt
+// columns (round numbers, or bar heights for vertical jumps) and each row's
+// Marks line up with them.
+type Sheet struct {
+	EventID   string
+	EventName string
+	Columns   []string
+	Rows      []Row
+}
+type Row struct {
+	Rank   int
+	Bib    string
+	Name   string
+	Club   string
+	Marks  []string
+	Best   string
+	Status string
+	Flags  []string
+}
+
+// Formats maps each format name to its file extension and writer.
+var Formats = map[string]struct {
+	Ext   string
+	Write func(io.Writer, Sheet) error
+}{
+	"csv": {".csv", WriteCSV},
+	"pdf": {".pdf", WritePDF},
+	"lif": {".lif", WriteLynx},
+}
+
+// place is the rank, or the status for athletes without one.
+func (r Row) place() string {
+	if r.Rank > 0 {
+		return fmt.Sprint(r.Rank)
+	}
+	return r.Status
+}
+
+// result is the best mark with any status and flags after it.
+func (r Row) result() string {
+	parts := []string{r.Best}
+	if r.Rank > 0 && r.Status != "" {
+		parts = append(parts, r.Status)
+	}
+	return strings.TrimSpace(strings.Join(append(parts, r.Flags...), " "))
+}
+
+// splitName splits "First Last" for formats with separate name fields.
+func splitName(name string) (first, last string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportEventResults(t *testing.T) {
+	event := Event{ID: "jt/w", Name: "Javelin Women", Athletes: []Athlete{{Bib: "1", Order: 1, Name: "Ada Byron", Club: "KACPH"}, {Bib: "2", Order: 2, Name: "Bo Lind"}}}
+	srv := newFakePolyFieldServer(t, []Event{event})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	if _, err := a.FetchEventDetails(host, port, "jt/w"); err != nil {
+		t.Fatal(err)
+	}
+	series := []Performance{{Attempt: 1, Mark: "41.20", Unit: "m", Valid: true}, {Attempt: 2, Status: "pass"}}
+	if err := a.PostResult(host, port, ResultPayload{EventID: "jt/w", AthleteBib: "1", Series: series}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "results")
+	paths, err := a.ExportEventResults("jt/w", dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 3 || filepath.Base(paths[0]) != "jt_w.csv" {
+		t.Fatalf("exported %v", paths)
+	}
+	csv, _ := os.ReadFile(paths[0])
+	want := "Place,Bib,Name,Club,1,2,Best,Status,Flags\n1,1,Ada Byron,KACPH,41.20,-,41.20,,\n,2,Bo Lind,,,,,,\n"
+	if string(csv) != want {
+		t.Fatalf("CSV\n%s\nwant\n%s", csv, want)
+	}
+	if _, err := a.ExportEventResults("jt/w", dir, []string{"xlsx"}); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+
+	hj := Event{ID: "hj", Name: "High Jump", Athletes: []Athlete{{Bib: "4", Order: 1, Name: "Cy Moss"}}}
+	if _, err := a.StartVerticalJump(hj, "HJ", []float64{1.60, 1.65}); err != nil {
+		t.Fatal(err)
+	}
+	a.RecordVerticalAttempt("hj", "4", 1.60, "X")
+	a.RecordVerticalAttempt("hj", "4", 1.60, "O")
+	paths, err = a.ExportEventResults("hj", dir, []string{"csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv, _ = os.ReadFile(paths[0])
+	if !strings.Contains(string(csv), "Place,Bib,Name,Club,1.60,1.65,Best") || !strings.Contains(string(csv), "1,4,Cy Moss,,XO,,1.60") {
+		t.Fatalf("vertical CSV\n%s", csv)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+
+	"PolyField/competition"
+)
+
+// --- Flights ---
+// Flights are kept with the event's entries and mirrored to the results
+// server, which is also where they come from when the event is fetched.
+const preliminaryRounds = 3
+
+type FlightTurn struct {
+	Flight  string `json:"flight"`
+	Bib     string `json:"bib,omitempty"`
+	Attempt int    `json:"attempt,omitempty"`
+	Done    bool   `json:"done"`
+}
+
+// SetEventFlights replaces the flight assignment for an event whose athletes
+// are known, then sends it to the server.
+func (a *App) SetEventFlights(eventID string, flights []Flight) error {
+	a.competitionMux.Lock()
+	he, ok := a.horizontals[eventID]
+	if !ok {
+		a.competitionMux.Unlock()
+		return fmt.Errorf("no athletes known for event '%s'", eventID)
+	}
+	if err := competition.ValidateFlights(flights, he.bibs()); err != nil {
+		a.competitionMux.Unlock()
+		return err
+	}
+	he.flights = append([]Flight(nil), flights...)
+	a.competitionMux.Unlock()
+	a.saveEvent(eventID)
+	return a.syncFlights(eventID, flights)
+}
+
+// SplitEventFlights divides the event's athletes, in start order, into
+// flights of at most size.
+func (a *App) SplitEventFlights(eventID string, size int) ([]Flight, error) {
+	a.competitionMux.Lock()
+	he, ok := a.horizontals[eventID]
+	var bibs []string
+	if ok {
+		bibs = he.bibs()
+	}
+	a.competitionMux.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no athletes known for event '%s'", eventID)
+	}
+	flights, err := competition.SplitFlights(bibs, size)
+	if err != nil {
+		return nil, err
+	}
+	return flights, a.SetEventFlights(eventID, flights)
+}
+func (a *App) GetEventFlights(eventID string) []Flight {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	if he, ok := a.horizontals[eventID]; ok {
+		return append([]Flight(nil), he.flights...)
+	}
+	return []Flight{}
+}
+
+// NextInFlight says who is up in a flight, given the attempts posted so far.
+// Athletes who are out (DNS, DNF, retired or DQ) are skipped.
+func (a *App) NextInFlight(eventID, flightName string, rounds int) (*FlightTurn, error) {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	flight, he, err := a.flight(eventID, flightName)
+	if err != nil {
+		return nil, err
+	}
+	attempts, out := he.progress()
+	bib, attempt, done := competition.NextInRotation(flight.Bibs, attempts, out, rounds)
+	return &FlightTurn{Flight: flight.Name, Bib: bib, Attempt: attempt, Done: done}, nil
+}
+
+// MergeFlightsToFinal ranks every flight together once all have had their
+// preliminary rounds and adds the final flight, ordered per the event's cut
+// rules.
+func (a *App) MergeFlightsToFinal(eventID string, rules EventRules) (*Flight, error) {
+	a.competitionMux.Lock()
+	he, ok := a.horizontals[eventID]
+	if !ok || len(he.flights) == 0 {
+		a.competitionMux.Unlock()
+		return nil, fmt.Errorf("event '%s' has no flights", eventID)
+	}
+	attempts, out := he.progress()
+	var prelims []Flight
+	for _, f := range he.flights {
+		if f.Name == competition.FinalFlight {
+			continue
+		}
+		if _, _, done := competition.NextInRotation(f.Bibs, attempts, out, preliminaryRounds); !done {
+			a.competitionMux.Unlock()
+			return nil, fmt.Errorf("flight %s has not finished its %d rounds", f.Name, preliminaryRounds)
+		}
+		prelims = append(prelims, f)
+	}
+	final := competition.MergeFinal(prelims, he.entryValues(), rules.CutQualifiers, rules.ReorderAfterCut)
+	he.flights = append(prelims, final)
+	flights := append([]Flight(nil), he.flights...)
+	a.competitionMux.Unlock()
+	a.saveEvent(eventID)
+	return &final, a.syncFlights(eventID, flights)
+}
+
+// flight must be called with competitionMux held.
+func (a *App) flight(eventID, name string) (Flight, *horizontalEvent, error) {
+	if he, ok := a.horizontals[eventID]; ok {
+		for _, f := range he.flights {
+			if f.Name == name {
+				return f, he, nil
+			}
+		}
+	}
+	return Flight{}, nil, fmt.Errorf("event '%s' has no flight '%s'", eventID, name)
+}
+
+// syncFlights sends the assignment to the results server when one is set.
+// The local copy stands either way.
+func (a *App) syncFlights(eventID string, flights []Flight) error {
+	a.stateMux.Lock()
+	serverAddr := a.serverAddress
+	a.stateMux.Unlock()
+	if serverAddr == "" {
+		return nil
+	}
+	if err := a.client.PutFlights(serverAddr, eventID, flights); err != nil {
+		return fmt.Errorf("flights saved locally, but the server did not get them: %w", err)
+	}
+	return nil
+}
+func (he *horizontalEvent) bibs() []string {
+	bibs := make([]string, len(he.entries))
+	for i, e := range he.entries {
+		bibs[i] = e.Bib
+	}
+	return bibs
+}
+
+// entryValues copies the entries with the attempts each athlete is allowed:
+// the event's, or only the preliminary rounds for those outside the final.
+func (he *horizontalEvent) entryValues() []competition.Entry {
+	var final map[string]bool
+	for _, f := range he.flights {
+		if f.Name == competition.FinalFlight {
+			final = make(map[string]bool, len(f.Bibs))
+			for _, bib := range f.Bibs {
+				final[bib] = true
+			}
+		}
+	}
+	entries := make([]competition.Entry, len(he.entries))
+	for i, e := range he.entries {
+		entries[i] = *e
+		entries[i].Attempts = he.event.Rules.Attempts
+		if final != nil && !final[e.Bib] {
+			entries[i].Attempts = preliminaryRounds
+		}
+	}
+	return entries
+}
+
+// progress counts attempts taken per athlete and who is out of the rotation.
+func (he *horizontalEvent) progress() (attempts map[string]int, out map[string]bool) {
+	attempts, out = make(map[string]int), make(map[string]bool)
+	for _, e := range he.entries {
+		attempts[e.Bib] = e.Taken()
+		if e.Out() {
+			out[e.Bib] = true
+		}
+	}
+	return attempts, out
+}
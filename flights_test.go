@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlightsRotateAndMergeIntoFinal(t *testing.T) {
+	var athletes []Athlete
+	for i, bib := range []string{"1", "2", "3", "4", "5"} {
+		athletes = append(athletes, Athlete{Bib: bib, Order: i + 1})
+	}
+	srv := newFakePolyFieldServer(t, []Event{{ID: "dt-m", Athletes: athletes}})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	a.SetServerAddress(host, port)
+	if _, err := a.FetchEventDetails(host, port, "dt-m"); err != nil {
+		t.Fatal(err)
+	}
+	flights, err := a.SplitEventFlights("dt-m", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.eventFlights("dt-m"); !reflect.DeepEqual(got, flights) || len(got) != 2 {
+		t.Fatalf("server has flights %+v, want %+v", got, flights)
+	}
+
+	marks := map[string]string{"1": "40.00", "2": "52.00", "3": "47.50", "4": "45.00", "5": "55.10"}
+	series := make(map[string][]Performance)
+	for {
+		turn, err := a.NextInFlight("dt-m", "A", preliminaryRounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if turn.Done {
+			break
+		}
+		if turn.Attempt != len(series[turn.Bib])+1 {
+			t.Fatalf("turn %+v after %d attempts", turn, len(series[turn.Bib]))
+		}
+		series[turn.Bib] = append(series[turn.Bib], Performance{Attempt: turn.Attempt, Mark: marks[turn.Bib], Unit: "m", Valid: true})
+		a.PostResult(host, port, ResultPayload{EventID: "dt-m", AthleteBib: turn.Bib, Series: series[turn.Bib]})
+	}
+	rules := EventRules{CutEnabled: true, CutQualifiers: 3, ReorderAfterCut: true}
+	if _, err := a.MergeFlightsToFinal("dt-m", rules); err == nil {
+		t.Fatal("merged before flight B had thrown")
+	}
+	for _, bib := range []string{"4", "5"} {
+		a.PostResult(host, port, ResultPayload{EventID: "dt-m", AthleteBib: bib, Series: []Performance{
+			{Attempt: 1, Mark: marks[bib], Unit: "m", Valid: true}, {Attempt: 2, Mark: "FOUL", Unit: "m"}, {Attempt: 3, Mark: "FOUL", Unit: "m"}}})
+	}
+	final, err := a.MergeFlightsToFinal("dt-m", rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Third-best throws first, the leader last.
+	if want := []string{"3", "2", "5"}; !reflect.DeepEqual(final.Bibs, want) {
+		t.Fatalf("final order %v, want %v", final.Bibs, want)
+	}
+	if got := srv.eventFlights("dt-m"); len(got) != 3 || got[2].Name != "Final" {
+		t.Fatalf("server flights after merge %+v", got)
+	}
+	if turn, _ := a.NextInFlight("dt-m", "Final", 6); turn.Bib != "3" || turn.Attempt != 4 {
+		t.Fatalf("first in the final: %+v", turn)
+	}
+}
+
+func TestNextInFlightIgnoresPlaceholderRounds(t *testing.T) {
+	a := newTestApp(t)
+	a.registerEvent(Event{ID: "sp-w", Athletes: []Athlete{{Bib: "1", Order: 1}, {Bib: "2", Order: 2}}})
+	if _, err := a.SplitEventFlights("sp-w", 2); err != nil {
+		t.Fatal(err)
+	}
+	for _, bib := range []string{"1", "2"} {
+		a.submitResult(ResultPayload{EventID: "sp-w", AthleteBib: bib, Series: []Performance{
+			{Attempt: 1, Mark: "11.00", Unit: "m", Valid: true}, {Attempt: 2, Unit: "m", Valid: true}, {Attempt: 3, Unit: "m", Valid: true}}})
+	}
+	if turn, err := a.NextInFlight("sp-w", "A", preliminaryRounds); err != nil || turn.Done || turn.Bib != "1" || turn.Attempt != 2 {
+		t.Fatalf("next turn %+v, %v", turn, err)
+	}
+}
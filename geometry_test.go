@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"PolyField/measurement"
+	"PolyField/simulator"
+)
+
+// scriptedEDM is an in-memory io.ReadWriteCloser that answers each read
+// command with a reading of whatever point the test has aimed it at.
+type scriptedEDM struct {
+	mu     sync.Mutex
+	cfg    simulator.EDMConfig
+	x, y   float64
+	reply  bytes.Buffer
+	closed bool
+	// moved, if set, is called after each reading with the lock held.
+	moved func(s *scriptedEDM)
+}
+
+func (s *scriptedEDM) aim(x, y float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.x, s.y = x, y
+}
+func (s *scriptedEDM) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if bytes.Equal(p, measurement.ReadCommand) {
+		sd, vaz, har := simulator.Observe(s.cfg, s.x, s.y)
+		fmt.Fprintf(&s.reply, "%.0f %s %s 0\r\n", sd, simulator.FormatDDDMMSS(vaz), simulator.FormatDDDMMSS(har))
+		if s.moved != nil {
+			s.moved(s)
+		}
+	}
+	return len(p), nil
+}
+func (s *scriptedEDM) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.reply.Len() == 0 {
+		return 0, io.EOF
+	}
+	return s.reply.Read(p)
+}
+func (s *scriptedEDM) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func newScriptedEDMApp(t *testing.T, cfg simulator.EDMConfig) (*App, *scriptedEDM) {
+	t.Helper()
+	a := newTestApp(t)
+	edm := &scriptedEDM{cfg: cfg}
+	attachTestDevice(a, "edm", "edm", edm)
+	t.Cleanup(func() { a.DisconnectDevice("edm") })
+	return a, edm
+}
+
+func TestSetCircleCentreLocatesStation(t *testing.T) {
+	tests := []simulator.EDMConfig{
+		{StationX: -4, StationY: -3, InstrumentHeight: 1.5},
+		{StationX: 10, StationY: 0, InstrumentHeight: 1.2},
+		{StationX: 0, StationY: 25, InstrumentHeight: 1.8},
+		{StationX: -30, StationY: 40, InstrumentHeight: 1.6},
+	}
+	for _, cfg := range tests {
+		a, _ := newScriptedEDMApp(t, cfg)
+		cal, err := a.SetCircleCentre("edm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(cal.StationCoordinates.X-cfg.StationX) > 0.002 || math.Abs(cal.StationCoordinates.Y-cfg.StationY) > 0.002 {
+			t.Errorf("station %+v computed as %+v", cfg, cal.StationCoordinates)
+		}
+	}
+}
+
+func TestVerifyCircleEdgeTolerance(t *testing.T) {
+	tests := []struct {
+		circle      string
+		radius      float64
+		offsetMm    float64
+		angle       float64
+		inTolerance bool
+	}{
+		{"SHOT", UkaRadiusShot, 0, 0, true},
+		{"SHOT", UkaRadiusShot, 4, 90, true},
+		{"DISCUS", UkaRadiusDiscus, -6, 200, false},
+		{"HAMMER", UkaRadiusHammer, 6.5, 315, false},
+		{"JAVELIN_ARC", UkaRadiusJavelinArc, 9, 10, true},
+		{"JAVELIN_ARC", UkaRadiusJavelinArc, -12, -20, false},
+	}
+	for _, tt := range tests {
+		a, edm := newScriptedEDMApp(t, simulator.EDMConfig{StationX: -5, StationY: -2, InstrumentHeight: 1.5})
+		if err := a.SaveCalibration("edm", EDMCalibrationData{DeviceID: "edm", SelectedCircleType: tt.circle, TargetRadius: tt.radius}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := a.SetCircleCentre("edm"); err != nil {
+			t.Fatal(err)
+		}
+		r := tt.radius + tt.offsetMm/1000
+		edm.aim(r*math.Cos(tt.angle*math.Pi/180), r*math.Sin(tt.angle*math.Pi/180))
+		cal, err := a.VerifyCircleEdge("edm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := cal.EdgeVerificationResult
+		if math.Abs(res.DifferenceMm-tt.offsetMm) > 1 {
+			t.Errorf("%s %+.1fmm: measured difference %.2fmm", tt.circle, tt.offsetMm, res.DifferenceMm)
+		}
+		if res.IsInTolerance != tt.inTolerance {
+			t.Errorf("%s %+.1fmm: in tolerance = %v, want %v", tt.circle, tt.offsetMm, res.IsInTolerance, tt.inTolerance)
+		}
+	}
+}
+
+func TestSaveCalibrationKeepsCentreTime(t *testing.T) {
+	a, _ := newScriptedEDMApp(t, simulator.EDMConfig{StationX: -5, StationY: -2, InstrumentHeight: 1.5})
+	cal, err := a.SetCircleCentre("edm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := *cal
+	edited.Timestamp = time.Time{}
+	edited.SelectedCircleType, edited.TargetRadius = "DISCUS", UkaRadiusDiscus
+	if err := a.SaveCalibration("edm", edited); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := a.GetCalibration("edm")
+	if !saved.Timestamp.Equal(cal.Timestamp) || saved.SelectedCircleType != "DISCUS" {
+		t.Fatalf("saved %+v, want centre time %v kept", saved, cal.Timestamp)
+	}
+}
+
+func TestVerifyCircleEdgeRequiresCentre(t *testing.T) {
+	a, _ := newScriptedEDMApp(t, simulator.DefaultEDMConfig())
+	if _, err := a.VerifyCircleEdge("edm"); err == nil {
+		t.Fatal("expected an error before the centre is set")
+	}
+	if _, err := a.MeasureThrow("edm"); err == nil {
+		t.Fatal("expected an error before the centre is set")
+	}
+}
+
+// For any station position and any landing point in front of the circle, the
+// measured throw equals the distance from the landing point to the circle
+// edge, to the centimetre.
+func TestMeasureThrowProperty(t *testing.T) {
+	f := func(sx, sy, h, dist, angle uint16) bool {
+		cfg := simulator.EDMConfig{
+			StationX:         float64(sx%4000)/100 - 20,
+			StationY:         float64(sy%4000)/100 - 20,
+			InstrumentHeight: 1 + float64(h%100)/100,
+		}
+		throw := 2 + float64(dist%9000)/100
+		theta := (float64(angle%600)/10 - 30) * math.Pi / 180
+		a, edm := newScriptedEDMApp(t, cfg)
+		if _, err := a.SetCircleCentre("edm"); err != nil {
+			t.Log(err)
+			return false
+		}
+		r := UkaRadiusShot + throw
+		edm.aim(r*math.Cos(theta), r*math.Sin(theta))
+		mark, err := a.MeasureThrow("edm")
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		got, err := strconv.ParseFloat(strings.TrimSuffix(mark, " m"), 64)
+		if err != nil || math.Abs(got-throw) > 0.006 {
+			t.Logf("station %+v, throw %.3f m: got %q", cfg, throw, mark)
+			return false
+		}
+		return true
+	}
+	// Each case pays the 250ms gap between the paired reads.
+	if err := quick.Check(f, &quick.Config{MaxCount: 8}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMeasureThrowRejectsInconsistentPair(t *testing.T) {
+	a, edm := newScriptedEDMApp(t, simulator.DefaultEDMConfig())
+	if _, err := a.SetCircleCentre("edm"); err != nil {
+		t.Fatal(err)
+	}
+	edm.aim(20, 0)
+	// The prism is moved 10cm between the two reads of the pair.
+	edm.mu.Lock()
+	edm.moved = func(s *scriptedEDM) { s.x += 0.1 }
+	edm.mu.Unlock()
+	mark, err := a.MeasureThrow("edm")
+	if err == nil || !strings.Contains(err.Error(), "inconsistent") {
+		t.Fatalf("MeasureThrow = %q, %v; want inconsistency error", mark, err)
+	}
+}
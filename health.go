@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"PolyField/devices"
+)
+
+// --- Device Health Monitoring ---
+const (
+	healthCheckInterval   = 5 * time.Second
+	DeviceStatusConnected = devices.StatusConnected
+	DeviceStatusDegraded  = devices.StatusDegraded
+	DeviceStatusLost      = devices.StatusLost
+	deviceStatusEvent     = "device-status"
+)
+
+type DeviceHealth = devices.Health
+
+func (a *App) GetDeviceStatuses() map[string]DeviceHealth {
+	a.devicesMux.RLock()
+	snapshot := make(map[string]*Device, len(a.devices))
+	for deviceID, dev := range a.devices {
+		snapshot[deviceID] = dev
+	}
+	a.devicesMux.RUnlock()
+	now := time.Now()
+	statuses := make(map[string]DeviceHealth, len(snapshot))
+	for deviceID, dev := range snapshot {
+		statuses[deviceID] = dev.Health(deviceID, now)
+	}
+	return statuses
+}
+func (a *App) checkDeviceHealth() {
+	a.devicesMux.RLock()
+	deviceIDs := make([]string, 0, len(a.devices))
+	snapshot := make(map[string]*Device, len(a.devices))
+	for deviceID, dev := range a.devices {
+		deviceIDs = append(deviceIDs, deviceID)
+		snapshot[deviceID] = dev
+	}
+	a.devicesMux.RUnlock()
+	sort.Strings(deviceIDs)
+	for _, deviceID := range deviceIDs {
+		dev := snapshot[deviceID]
+		driver, ok := devices.Drivers[dev.Type]
+		if !ok || dev.Conn == nil {
+			continue
+		}
+		// A busy device has a transaction in flight that will report its
+		// own outcome. A passing probe proves only the link, so it leaves
+		// the device's last reply and error run as they were.
+		if err := driver.Probe(dev); err != nil && !errors.Is(err, devices.ErrBusy) {
+			dev.RecordFailure(fmt.Errorf("probe failed: %w", err))
+		}
+	}
+	a.emitEvent(deviceStatusEvent, a.GetDeviceStatuses())
+}
+func (a *App) monitorDevices() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		a.checkDeviceHealth()
+	}
+}
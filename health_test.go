@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+// A probe that only checks the link must not make a silent device look
+// healthy: its last reply and error run stay as they were.
+func TestProbeDoesNotMarkSilentDeviceSeen(t *testing.T) {
+	a := newTestApp(t)
+	conn, peer := net.Pipe()
+	peer.Close()
+	dev := &Device{Conn: conn, Type: "edm", ConnectionType: "network", Address: "10.0.0.9:4001"}
+	a.devicesMux.Lock()
+	a.attachDevice("edm", dev)
+	a.devicesMux.Unlock()
+	t.Cleanup(func() { dev.Close() })
+	dev.RecordFailure(errors.New("no reply"))
+	before := dev.Health("edm", dev.LastSeen())
+	a.checkDeviceHealth()
+	after := dev.Health("edm", dev.LastSeen())
+	if !after.LastSeen.Equal(before.LastSeen) || after.ConsecutiveErrors != 1 || after.Status != DeviceStatusDegraded {
+		t.Fatalf("health after probing %+v, before %+v", after, before)
+	}
+}
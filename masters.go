@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"PolyField/competition"
+)
+
+// --- Masters Competition ---
+// Events may name their age group and implement. Masters throws are graded
+// against the WMA factors once a factor file has been loaded, and the
+// percentage travels with each result.
+type mastersEvent struct {
+	sex        string
+	discipline string
+	ages       map[string]int
+}
+
+// LoadAgeGradingTables reads a WMA age factor file (see README).
+func (a *App) LoadAgeGradingTables(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	factors, err := competition.LoadAgeFactors(f)
+	if err != nil {
+		return err
+	}
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	a.ageFactors = factors
+	return nil
+}
+
+// AgeGradeMark grades a mark such as "12.34" for a sex (M or W), discipline
+// and age.
+func (a *App) AgeGradeMark(sex, discipline string, age int, mark string) (float64, error) {
+	cm, ok := competition.ParseMark(mark)
+	if !ok {
+		return 0, fmt.Errorf("invalid mark '%s'", mark)
+	}
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	if a.ageFactors == nil {
+		return 0, fmt.Errorf("no age grading tables loaded")
+	}
+	return a.ageFactors.Grade(sex, discipline, age, cm)
+}
+
+// CheckEventCircle reports whether the EDM is calibrated for the circle the
+// event's implement is thrown from. MeasureThrow makes the same check for
+// the event the EDM is measuring.
+func (a *App) CheckEventCircle(deviceID string, event Event) error {
+	if event.Implement == nil {
+		return fmt.Errorf("event '%s' has no implement", event.ID)
+	}
+	cal, ok := a.calibrations.Get(deviceID)
+	if !ok {
+		return fmt.Errorf("EDM '%s' is not calibrated", deviceID)
+	}
+	return checkEventCircle(deviceID, cal, event)
+}
+func checkEventCircle(deviceID string, cal EDMCalibrationData, event Event) error {
+	circle, err := competition.ImplementCircle(event.Implement.Type)
+	if err != nil {
+		return err
+	}
+	if cal.SelectedCircleType != circle {
+		return fmt.Errorf("EDM '%s' is calibrated for %s but event '%s' needs %s", deviceID, cal.SelectedCircleType, event.ID, circle)
+	}
+	return nil
+}
+
+// measuredEvent is the event an EDM is measuring: its station's event, or
+// else the live event on the overlay.
+func (a *App) measuredEvent(deviceID string) (Event, bool) {
+	eventID, _ := a.measuredAthlete(deviceID)
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	he, ok := a.horizontals[eventID]
+	if !ok {
+		return Event{}, false
+	}
+	return he.event, true
+}
+
+// registerMasters remembers masters throws for grading. The event has been
+// checked by registerEvent.
+func (a *App) registerMasters(event Event) {
+	sex, _, ok := competition.ParseMastersGroup(event.AgeGroup)
+	if !ok || event.Implement == nil {
+		return
+	}
+	me := &mastersEvent{sex: sex, discipline: competition.ImplementDisciplines[event.Implement.Type], ages: make(map[string]int)}
+	for _, ath := range event.Athletes {
+		if ath.Age > 0 {
+			me.ages[ath.Bib] = ath.Age
+		}
+	}
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	a.mastersEvents[event.ID] = me
+}
+
+// ageGrade fills in the payload's age-graded percentage for a masters event.
+func (a *App) ageGrade(payload *ResultPayload) {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	me, ok := a.mastersEvents[payload.EventID]
+	if !ok || a.ageFactors == nil {
+		return
+	}
+	age, ok := me.ages[payload.AthleteBib]
+	cm, marked := competition.BestMark(payload.Series)
+	if !ok || !marked {
+		return
+	}
+	graded, err := a.ageFactors.Grade(me.sex, me.discipline, age, cm)
+	if err != nil {
+		log.Printf("Age grading bib %s in event %s: %v", payload.AthleteBib, payload.EventID, err)
+		return
+	}
+	payload.AgeGraded = graded
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMastersEventIsAgeGraded(t *testing.T) {
+	event := Event{ID: "sp-m60", AgeGroup: "M60", Implement: &Implement{Type: "SHOT", Weight: 5},
+		Athletes: []Athlete{{Bib: "60", Order: 1, Age: 61}, {Bib: "61", Order: 2}}}
+	srv := newFakePolyFieldServer(t, []Event{event})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	if _, err := a.FetchEventDetails(host, port, "sp-m60"); err != nil {
+		t.Fatal(err)
+	}
+	// Made-up factors, not WMA values.
+	path := filepath.Join(t.TempDir(), "factors.json")
+	if err := os.WriteFile(path, []byte(`{"tables": [{"sex": "M", "discipline": "SP", "openStandard": "20.00", "factors": {"61": 0.8}}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.LoadAgeGradingTables(path); err != nil {
+		t.Fatal(err)
+	}
+	if pct, err := a.AgeGradeMark("M", "SP", 61, "14.00"); err != nil || pct != 87.5 {
+		t.Fatalf("AgeGradeMark = %v, %v", pct, err)
+	}
+	for _, bib := range []string{"60", "61"} {
+		series := []Performance{{Attempt: 1, Mark: "12.00", Unit: "m", Valid: true}}
+		if err := a.PostResult(host, port, ResultPayload{EventID: "sp-m60", AthleteBib: bib, Series: series}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := srv.results()
+	if got[0].AgeGraded != 75 || got[1].AgeGraded != 0 {
+		t.Fatalf("age-graded results %+v", got)
+	}
+
+	if err := a.CheckEventCircle("edm-1", event); err == nil {
+		t.Fatal("uncalibrated EDM accepted")
+	}
+	if _, err := a.SelectCircle("edm-1", "DISCUS"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckEventCircle("edm-1", event); err == nil {
+		t.Fatal("discus circle accepted for the shot")
+	}
+	a.SelectCircle("edm-1", "SHOT")
+	if err := a.CheckEventCircle("edm-1", event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventChecksBlockRegistrationAndMeasuring(t *testing.T) {
+	heavy := Event{ID: "sp-w", AgeGroup: "W35", Implement: &Implement{Type: "SHOT", Weight: 8}, Athletes: []Athlete{{Bib: "1", Order: 1}}}
+	srv := newFakePolyFieldServer(t, []Event{heavy})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	if _, err := a.FetchEventDetails(host, port, "sp-w"); err == nil {
+		t.Fatal("non-standard implement weight accepted")
+	}
+	if got := a.GetStandings("sp-w"); len(got) != 0 {
+		t.Fatalf("rejected event tracked: %+v", got)
+	}
+	path := filepath.Join(t.TempDir(), "events.json")
+	os.WriteFile(path, []byte(`[{"id": "dt-m", "ageGroup": "M30", "athletes": [{"bib": "5", "order": 1}]}]`), 0644)
+	if _, err := a.ImportStartList(path, "", ""); err == nil {
+		t.Fatal("masters group under 35 imported")
+	}
+
+	shot := Event{ID: "sp-m", Implement: &Implement{Type: "SHOT", Weight: 7.26}, Athletes: []Athlete{{Bib: "2", Order: 1}}}
+	if err := a.registerEvent(shot); err != nil {
+		t.Fatal(err)
+	}
+	a.SaveCalibration("edm", EDMCalibrationData{SelectedCircleType: "DISCUS", TargetRadius: UkaRadiusDiscus, IsCentreSet: true})
+	if err := a.SaveStation(Station{Name: "Circle 1", EDM: "edm", EventID: "sp-m"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.MeasureThrow("edm"); err == nil || !strings.Contains(err.Error(), "needs SHOT") {
+		t.Fatalf("measured the shot from the discus circle: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package measurement
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// --- Reading Consistency & Wind Averaging ---
+const (
+	SDToleranceMm           = 3.0
+	DelayBetweenReadsInPair = 250 * time.Millisecond
+	WindWindow              = 5 * time.Second
+)
+
+type WindReading struct {
+	Value     float64
+	Timestamp time.Time
+}
+
+// ReadPair takes two readings and averages them if their slope distances
+// agree, which catches a prism that moved or a beam that was interrupted.
+func ReadPair(read func() (*ParsedReading, error)) (*AveragedReading, error) {
+	r1, err := read()
+	if err != nil {
+		return nil, fmt.Errorf("first read failed: %w", err)
+	}
+	time.Sleep(DelayBetweenReadsInPair)
+	r2, err := read()
+	if err != nil {
+		return nil, fmt.Errorf("second read failed: %w", err)
+	}
+	if math.Abs(r1.SlopeDistanceMm-r2.SlopeDistanceMm) > SDToleranceMm {
+		return nil, fmt.Errorf("readings inconsistent. R1(SD): %.0fmm, R2(SD): %.0fmm", r1.SlopeDistanceMm, r2.SlopeDistanceMm)
+	}
+	return &AveragedReading{
+		SlopeDistanceMm: (r1.SlopeDistanceMm + r2.SlopeDistanceMm) / 2.0,
+		VAzDecimal:      (r1.VAzDecimal + r2.VAzDecimal) / 2.0,
+		HARDecimal:      (r1.HARDecimal + r2.HARDecimal) / 2.0,
+	}, nil
+}
+
+// AverageWind averages the readings taken in the WindWindow before now.
+func AverageWind(readings []WindReading, now time.Time) (float64, error) {
+	since := now.Add(-WindWindow)
+	var sum float64
+	var n int
+	for _, r := range readings {
+		if r.Timestamp.After(since) {
+			sum += r.Value
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("no wind readings in the last 5 seconds")
+	}
+	return sum / float64(n), nil
+}
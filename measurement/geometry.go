@@ -0,0 +1,69 @@
+package measurement
+
+import "math"
+
+// --- Circle Geometry ---
+const (
+	UkaRadiusShot           = 1.0675
+	UkaRadiusDiscus         = 1.250
+	UkaRadiusHammer         = 1.0675
+	UkaRadiusJavelinArc     = 8.000
+	ToleranceThrowsCircleMm = 5.0
+	ToleranceJavelinMm      = 10.0
+)
+
+// CircleRadii maps each circle type to its UKA radius in metres.
+var CircleRadii = map[string]float64{
+	"SHOT":        UkaRadiusShot,
+	"DISCUS":      UkaRadiusDiscus,
+	"HAMMER":      UkaRadiusHammer,
+	"JAVELIN_ARC": UkaRadiusJavelinArc,
+}
+
+type Point struct{ X, Y float64 }
+type EdgeVerificationResult struct {
+	MeasuredRadius, DifferenceMm, ToleranceAppliedMm float64
+	IsInTolerance                                    bool
+}
+
+// offset is the horizontal vector from the instrument to the prism.
+func offset(r AveragedReading) (x, y float64) {
+	sdMeters := r.SlopeDistanceMm / 1000.0
+	vazRad := r.VAzDecimal * math.Pi / 180.0
+	harRad := r.HARDecimal * math.Pi / 180.0
+	hd := sdMeters * math.Sin(vazRad)
+	return hd * math.Cos(harRad), hd * math.Sin(harRad)
+}
+
+// StationFromCentre places the instrument relative to the circle centre from
+// a reading taken with the prism on the centre.
+func StationFromCentre(r AveragedReading) Point {
+	x, y := offset(r)
+	return Point{X: -x, Y: -y}
+}
+
+// RadiusFromCentre is how far from the circle centre the prism was.
+func RadiusFromCentre(station Point, r AveragedReading) float64 {
+	x, y := offset(r)
+	return math.Hypot(station.X+x, station.Y+y)
+}
+
+// ToleranceMm is the allowed edge error for a circle type.
+func ToleranceMm(circleType string) float64 {
+	if circleType == "JAVELIN_ARC" {
+		return ToleranceJavelinMm
+	}
+	return ToleranceThrowsCircleMm
+}
+
+func VerifyEdge(station Point, targetRadius float64, circleType string, r AveragedReading) EdgeVerificationResult {
+	measured := RadiusFromCentre(station, r)
+	diffMm := (measured - targetRadius) * 1000.0
+	tolerance := ToleranceMm(circleType)
+	return EdgeVerificationResult{MeasuredRadius: measured, DifferenceMm: diffMm, IsInTolerance: math.Abs(diffMm) <= tolerance, ToleranceAppliedMm: tolerance}
+}
+
+// ThrowDistance is the mark: landing point to the inside of the circle edge.
+func ThrowDistance(station Point, targetRadius float64, r AveragedReading) float64 {
+	return RadiusFromCentre(station, r) - targetRadius
+}
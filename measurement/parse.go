@@ -0,0 +1,84 @@
+// Package measurement turns raw EDM and wind gauge output into readings and
+// readings into distances. It does no I/O of its own.
+package measurement
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ReadCommand asks the EDM for one slope distance / angle reading.
+var ReadCommand = []byte{0x11, 0x0d, 0x0a}
+
+type ParsedReading struct{ SlopeDistanceMm, VAzDecimal, HARDecimal float64 }
+type AveragedReading struct{ SlopeDistanceMm, VAzDecimal, HARDecimal float64 }
+
+// ParseDDDMMSSAngle decodes the EDM's packed degrees/minutes/seconds angle.
+func ParseDDDMMSSAngle(angleStr string) (float64, error) {
+	if len(angleStr) < 6 || len(angleStr) > 7 {
+		return 0, fmt.Errorf("invalid angle string length: got %d for '%s'", len(angleStr), angleStr)
+	}
+	if len(angleStr) == 6 {
+		angleStr = "0" + angleStr
+	}
+	for _, c := range angleStr {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid angle string '%s': expected digits only", angleStr)
+		}
+	}
+	ddd, err := strconv.Atoi(angleStr[0:3])
+	if err != nil {
+		return 0, err
+	}
+	mm, err := strconv.Atoi(angleStr[3:5])
+	if err != nil {
+		return 0, err
+	}
+	ss, err := strconv.Atoi(angleStr[5:7])
+	if err != nil {
+		return 0, err
+	}
+	if mm >= 60 || ss >= 60 {
+		return 0, fmt.Errorf("invalid angle values (MM or SS >= 60) in '%s'", angleStr)
+	}
+	return float64(ddd) + (float64(mm) / 60.0) + (float64(ss) / 3600.0), nil
+}
+
+// ParseEDMResponse decodes "SDmm VAZ HAR status".
+func ParseEDMResponse(raw string) (*ParsedReading, error) {
+	parts := strings.Fields(strings.TrimSpace(raw))
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("malformed response, got %d parts", len(parts))
+	}
+	sd, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	if sd < 0 || math.IsNaN(sd) || math.IsInf(sd, 0) {
+		return nil, fmt.Errorf("invalid slope distance '%s'", parts[0])
+	}
+	vaz, err := ParseDDDMMSSAngle(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	har, err := ParseDDDMMSSAngle(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	return &ParsedReading{SlopeDistanceMm: sd, VAzDecimal: vaz, HARDecimal: har}, nil
+}
+
+// ParseWindResponse extracts the signed speed from a gauge record such as
+// "WS,+1.23,M".
+func ParseWindResponse(raw string) (float64, bool) {
+	parts := strings.Split(strings.TrimSpace(raw), ",")
+	if len(parts) > 1 && (strings.HasPrefix(parts[1], "+") || strings.HasPrefix(parts[1], "-")) {
+		val, err := strconv.ParseFloat(parts[1], 64)
+		if err == nil && !math.IsNaN(val) && !math.IsInf(val, 0) {
+			return val, true
+		}
+	}
+	return 0, false
+}
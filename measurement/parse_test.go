@@ -0,0 +1,165 @@
+package measurement
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"PolyField/simulator"
+)
+
+func TestParseDDDMMSSAngle(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "0923000", want: 92.5},
+		{in: "923000", want: 92.5},
+		{in: "0000000", want: 0},
+		{in: "3595959", want: 359 + 59.0/60 + 59.0/3600},
+		{in: "0450045", want: 45.0125},
+		{in: "12345", wantErr: true},
+		{in: "12345678", wantErr: true},
+		{in: "0926000", wantErr: true},
+		{in: "0923060", wantErr: true},
+		{in: "09230ab", wantErr: true},
+		{in: "-995959", wantErr: true},
+		{in: "+12345", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDDDMMSSAngle(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDDDMMSSAngle(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ParseDDDMMSSAngle(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// Any angle the instrument can display must parse back to within half an
+// arcsecond of itself.
+func TestParseDDDMMSSAngleRoundTrip(t *testing.T) {
+	f := func(raw uint32) bool {
+		deg := float64(raw%(360*3600)) / 3600
+		got, err := ParseDDDMMSSAngle(simulator.FormatDDDMMSS(deg))
+		return err == nil && math.Abs(got-deg) < 0.5/3600
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseEDMResponseString(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    ParsedReading
+		wantErr bool
+	}{
+		{name: "typical", in: "10234 0923000 1800000 83", want: ParsedReading{SlopeDistanceMm: 10234, VAzDecimal: 92.5, HARDecimal: 180}},
+		{name: "line ending and padding", in: "  10234  0923000 1800000 83\r\n", want: ParsedReading{SlopeDistanceMm: 10234, VAzDecimal: 92.5, HARDecimal: 180}},
+		{name: "six digit angles", in: "5000 923000 450000 0", want: ParsedReading{SlopeDistanceMm: 5000, VAzDecimal: 92.5, HARDecimal: 45}},
+		{name: "extra fields", in: "5000 0923000 0450000 0 X", want: ParsedReading{SlopeDistanceMm: 5000, VAzDecimal: 92.5, HARDecimal: 45}},
+		{name: "too few fields", in: "10234 0923000 1800000", wantErr: true},
+		{name: "error code", in: "E105 ??", wantErr: true},
+		{name: "bad distance", in: "10x34 0923000 1800000 83", wantErr: true},
+		{name: "negative distance", in: "-10 0923000 1800000 83", wantErr: true},
+		{name: "infinite distance", in: "Inf 0923000 1800000 83", wantErr: true},
+		{name: "bad zenith", in: "10234 0926000 1800000 83", wantErr: true},
+		{name: "bad horizontal", in: "10234 0923000 18000 83", wantErr: true},
+		{name: "empty", in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEDMResponse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWindResponse(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   float64
+		wantOK bool
+	}{
+		{"WS,+1.23,M", 1.23, true},
+		{"WS,-0.50,M\r\n", -0.5, true},
+		{"0,+0.0", 0, true},
+		{"WS,1.2,M", 0, false},
+		{"WS,ERR", 0, false},
+		{"WS,+,M", 0, false},
+		{"WS,+Inf,M", 0, false},
+		{"+1.2", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseWindResponse(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseWindResponse(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func FuzzParseDDDMMSSAngle(f *testing.F) {
+	for _, seed := range []string{"0923000", "923000", "3595959", "0926000", "-12345", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParseDDDMMSSAngle(s)
+		if err != nil {
+			return
+		}
+		if got < 0 || got >= 1000 {
+			t.Fatalf("ParseDDDMMSSAngle(%q) = %v, outside 0–1000°", s, got)
+		}
+		if back := simulator.FormatDDDMMSS(got); got < 360 && strings.TrimLeft(back, "0") != strings.TrimLeft(s, "0") {
+			t.Fatalf("ParseDDDMMSSAngle(%q) = %v, which formats back as %q", s, got, back)
+		}
+	})
+}
+
+func FuzzParseEDMResponse(f *testing.F) {
+	for _, seed := range []string{"10234 0923000 1800000 83\r\n", "E105 ??", "1 2 3 4", "NaN 0923000 1800000 0"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParseEDMResponse(s)
+		if err != nil {
+			return
+		}
+		if got.SlopeDistanceMm < 0 || math.IsNaN(got.SlopeDistanceMm) || math.IsInf(got.SlopeDistanceMm, 0) {
+			t.Fatalf("ParseEDMResponse(%q) accepted slope distance %v", s, got.SlopeDistanceMm)
+		}
+	})
+}
+
+func FuzzParseWindResponse(f *testing.F) {
+	for _, seed := range []string{"WS,+1.23,M", "WS,-0.5", "WS,ERR", ",+", "WS,+NaN"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got, ok := ParseWindResponse(s)
+		if ok && (math.IsNaN(got) || math.IsInf(got, 0)) {
+			t.Fatalf("ParseWindResponse(%q) accepted %v", s, got)
+		}
+	})
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// --- Live Results Overlay Server ---
+const (
+	overlayClientBuffer = 8
+	overlayShutdownWait = 2 * time.Second
+)
+
+type OverlayAthlete struct {
+	Bib  string `json:"bib"`
+	Name string `json:"name"`
+	Club string `json:"club,omitempty"`
+}
+type OverlayStanding struct {
+	Rank   int    `json:"rank"`
+	Bib    string `json:"bib"`
+	Name   string `json:"name"`
+	Club   string `json:"club,omitempty"`
+	Best   string `json:"best"`
+	Status string `json:"status,omitempty"`
+}
+
+// OverlayState is everything a broadcast graphic or trackside screen needs
+// about the event currently in progress.
+type OverlayState struct {
+	EventID    string          `json:"eventId"`
+	EventName  string          `json:"eventName"`
+	Athlete    *OverlayAthlete `json:"athlete,omitempty"`
+	Attempt    int             `json:"attempt,omitempty"`
+	LatestMark string          `json:"latestMark,omitempty"`
+	Flags      []string        `json:"flags,omitempty"`
+	Wind       string          `json:"wind,omitempty"`
+	// Points and TotalPoints score the latest mark in a combined event.
+	Points      int               `json:"points,omitempty"`
+	TotalPoints int               `json:"totalPoints,omitempty"`
+	Standings   []OverlayStanding `json:"standings"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+type overlayServer struct {
+	server  *http.Server
+	url     string
+	mu      sync.Mutex
+	clients map[chan []byte]bool
+}
+
+// overlayData is the App's live event state, guarded by overlayMux.
+type overlayData struct {
+	overlayMux      sync.Mutex
+	overlay         OverlayState
+	overlayAthletes map[string]Athlete
+	overlayServer   *overlayServer
+}
+
+func (a *App) StartOverlayServer(port int) (string, error) {
+	a.overlayMux.Lock()
+	defer a.overlayMux.Unlock()
+	if a.overlayServer != nil {
+		return a.overlayServer.url, nil
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return "", fmt.Errorf("could not start overlay server: %w", err)
+	}
+	srv := &overlayServer{clients: make(map[chan []byte]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", srv.handleIndex)
+	mux.HandleFunc("/api/live", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(a.overlaySnapshot())
+	})
+	// A websocket.Server without a Handshake accepts any Origin, which graphics
+	// machines on the broadcast network need.
+	mux.Handle("/ws", websocket.Server{Handler: func(ws *websocket.Conn) { srv.serveClient(ws, a.overlaySnapshot()) }})
+	srv.server = &http.Server{Handler: mux}
+	srv.url = fmt.Sprintf("http://%s/", overlayHostAddress(ln.Addr().(*net.TCPAddr).Port))
+	go func() {
+		if err := srv.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("Overlay server stopped: %v", err)
+		}
+	}()
+	a.overlayServer = srv
+	return srv.url, nil
+}
+func (a *App) StopOverlayServer() error {
+	a.overlayMux.Lock()
+	srv := a.overlayServer
+	a.overlayServer = nil
+	a.overlayMux.Unlock()
+	if srv == nil {
+		return fmt.Errorf("overlay server is not running")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), overlayShutdownWait)
+	defer cancel()
+	srv.closeClients()
+	return srv.server.Shutdown(ctx)
+}
+
+// overlayHostAddress picks the first non-loopback IPv4 address so the URL
+// shown to officials works from other machines on the network.
+func overlayHostAddress(port int) string {
+	host := "localhost"
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				host = ipnet.IP.String()
+				break
+			}
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (s *overlayServer) serveClient(ws *websocket.Conn, initial []byte) {
+	updates := make(chan []byte, overlayClientBuffer)
+	s.mu.Lock()
+	s.clients[updates] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, updates)
+		s.mu.Unlock()
+	}()
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, ws)
+		close(closed)
+	}()
+	if err := websocket.Message.Send(ws, string(initial)); err != nil {
+		return
+	}
+	for {
+		select {
+		case msg, ok := <-updates:
+			if !ok {
+				ws.Close()
+				return
+			}
+			if err := websocket.Message.Send(ws, string(msg)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+func (s *overlayServer) broadcast(msg []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.clients {
+		select {
+		case ch <- msg:
+		default:
+			// A client that has fallen behind only needs the latest state.
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- msg
+		}
+	}
+}
+func (s *overlayServer) closeClients() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.clients {
+		close(ch)
+		delete(s.clients, ch)
+	}
+}
+
+func (a *App) overlaySnapshot() []byte {
+	a.overlayMux.Lock()
+	defer a.overlayMux.Unlock()
+	data, _ := json.Marshal(a.overlay)
+	return data
+}
+
+// updateOverlay applies a change to the live state and pushes it to every
+// connected client. The push happens under the lock, which broadcast never
+// blocks, so clients see the updates in the order they were made.
+func (a *App) updateOverlay(change func(s *OverlayState)) {
+	a.overlayMux.Lock()
+	defer a.overlayMux.Unlock()
+	change(&a.overlay)
+	a.overlay.UpdatedAt = time.Now().UTC()
+	if a.overlay.Standings == nil {
+		a.overlay.Standings = []OverlayStanding{}
+	}
+	data, _ := json.Marshal(a.overlay)
+	if a.overlayServer != nil {
+		a.overlayServer.broadcast(data)
+	}
+}
+func (a *App) GetOverlayState() OverlayState {
+	a.overlayMux.Lock()
+	defer a.overlayMux.Unlock()
+	return a.overlay
+}
+
+// SetOverlayEvent starts a new live event. Athletes are kept so that results
+// posted by bib can be shown with names.
+func (a *App) SetOverlayEvent(event Event) {
+	a.overlayMux.Lock()
+	a.overlayAthletes = make(map[string]Athlete, len(event.Athletes))
+	for _, ath := range event.Athletes {
+		a.overlayAthletes[ath.Bib] = ath
+	}
+	a.overlayMux.Unlock()
+	a.updateOverlay(func(s *OverlayState) {
+		*s = OverlayState{EventID: event.ID, EventName: event.Name}
+	})
+}
+func (a *App) SetOverlayAthlete(bib string, attempt int) {
+	a.overlayMux.Lock()
+	ath, ok := a.overlayAthletes[bib]
+	a.overlayMux.Unlock()
+	if !ok {
+		ath = Athlete{Bib: bib}
+	}
+	a.updateOverlay(func(s *OverlayState) {
+		s.Athlete = &OverlayAthlete{Bib: ath.Bib, Name: ath.Name, Club: ath.Club}
+		s.Attempt = attempt
+		s.LatestMark = ""
+		s.Flags = nil
+		s.Points, s.TotalPoints = 0, 0
+		s.Wind = ""
+	})
+}
+
+// setOverlayStandings shows the standings when they are for the live event.
+func (a *App) setOverlayStandings(eventID string, standings []Standing) {
+	a.overlayMux.Lock()
+	if eventID != a.overlay.EventID {
+		a.overlayMux.Unlock()
+		return
+	}
+	rows := make([]OverlayStanding, len(standings))
+	for i, st := range standings {
+		ath := a.overlayAthletes[st.Bib]
+		rows[i] = OverlayStanding{Rank: st.Rank, Bib: st.Bib, Name: ath.Name, Club: ath.Club, Best: st.Best, Status: st.Status}
+	}
+	a.overlayMux.Unlock()
+	a.updateOverlay(func(s *OverlayState) { s.Standings = rows })
+}
+
+func (s *overlayServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, overlayHTML)
+}
+
+// overlayHTML is a deliberately plain lower-third that can be keyed over
+// video or shown full-screen on a trackside display.
+const overlayHTML = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>PolyField Live</title>
+<style>
+body{margin:0;font-family:Helvetica,Arial,sans-serif;background:transparent;color:#fff}
+#bar{position:fixed;left:40px;right:40px;bottom:40px;background:rgba(17,24,39,.9);border-radius:8px;padding:16px 24px;display:flex;align-items:center;gap:32px}
+#event{font-size:14px;text-transform:uppercase;opacity:.7}
+#athlete{font-size:32px;font-weight:bold;flex:1}
+#mark{font-size:48px;font-weight:bold;color:#facc15}
+#wind,#attempt,#points{font-size:20px;opacity:.85}
+#standings{position:fixed;top:40px;right:40px;background:rgba(17,24,39,.9);border-radius:8px;padding:12px 20px;min-width:280px}
+#standings td{padding:2px 8px;font-size:18px}
+</style></head>
+<body>
+<table id="standings"></table>
+<div id="bar"><div><div id="event"></div><div id="athlete"></div><div id="attempt"></div></div><div id="mark"></div><div id="points"></div><div id="wind"></div></div>
+<script>
+function esc(s){return String(s||'').replace(/[&<>"]/g,function(c){return {'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;'}[c]})}
+function render(s){
+  document.getElementById('event').textContent=s.eventName||'';
+  var a=s.athlete||{};
+  document.getElementById('athlete').textContent=[a.bib,a.name].filter(Boolean).join('  ');
+  document.getElementById('attempt').textContent=s.attempt?('Attempt '+s.attempt):'';
+  document.getElementById('mark').textContent=[s.latestMark||''].concat(s.flags||[]).join(' ');
+  document.getElementById('points').textContent=s.points?(s.points+' pts / '+s.totalPoints):'';
+  document.getElementById('wind').textContent=s.wind||'';
+  document.getElementById('standings').innerHTML=(s.standings||[]).slice(0,8).map(function(r){
+    return '<tr><td>'+(r.rank||'')+'</td><td>'+esc(r.name||r.bib)+'</td><td>'+esc(r.best||r.status||'')+'</td></tr>'}).join('');
+}
+function connect(){
+  var ws=new WebSocket((location.protocol==='https:'?'wss://':'ws://')+location.host+'/ws');
+  ws.onmessage=function(e){render(JSON.parse(e.data))};
+  ws.onclose=function(){setTimeout(connect,2000)};
+}
+connect();
+</script></body></html>`
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestOverlayUpdatesReachClientsInOrder(t *testing.T) {
+	a := newTestApp(t)
+	updates := make(chan []byte, 1000)
+	a.overlayServer = &overlayServer{clients: map[chan []byte]bool{updates: true}}
+	var wg sync.WaitGroup
+	seq := 0
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				// The change runs under the overlay lock, so seq counts updates.
+				a.updateOverlay(func(s *OverlayState) { seq++; s.Attempt = seq })
+			}
+		}()
+	}
+	wg.Wait()
+	close(updates)
+	last := 0
+	for msg := range updates {
+		var state OverlayState
+		if err := json.Unmarshal(msg, &state); err != nil {
+			t.Fatal(err)
+		}
+		if state.Attempt <= last {
+			t.Fatalf("update %d arrived after %d", state.Attempt, last)
+		}
+		last = state.Attempt
+	}
+	if last != 400 {
+		t.Fatalf("last update %d, want 400", last)
+	}
+}
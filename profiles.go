@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"PolyField/devices"
+)
+
+// --- Serial Configuration & Device Profiles ---
+const (
+	profilesFileName  = "polyfield_device_profiles.json"
+	FlowControlNone   = devices.FlowControlNone
+	FlowControlRTSCTS = devices.FlowControlRTSCTS
+)
+
+type SerialConfig = devices.SerialConfig
+type DeviceProfile struct {
+	Name             string       `json:"name"`
+	DevType          string       `json:"devType"`
+	Serial           SerialConfig `json:"serial"`
+	ScoreboardDriver string       `json:"scoreboardDriver,omitempty"`
+}
+
+func DefaultSerialConfig() SerialConfig { return devices.DefaultSerialConfig() }
+
+func (a *App) ListDeviceProfiles() []DeviceProfile {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	profiles := make([]DeviceProfile, 0, len(a.profiles))
+	for _, p := range a.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+func (a *App) SaveDeviceProfile(profile DeviceProfile) error {
+	if strings.TrimSpace(profile.Name) == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if _, ok := devices.Drivers[profile.DevType]; !ok {
+		return fmt.Errorf("unknown device type '%s': use edm, wind or scoreboard", profile.DevType)
+	}
+	profile.Serial = profile.Serial.WithDefaults()
+	if _, err := profile.Serial.Mode(); err != nil {
+		return fmt.Errorf("invalid serial settings: %w", err)
+	}
+	if _, ok := devices.ScoreboardDrivers[profile.ScoreboardDriver]; profile.ScoreboardDriver != "" && !ok {
+		return fmt.Errorf("unknown scoreboard driver '%s'", profile.ScoreboardDriver)
+	}
+	if profile.ScoreboardDriver != "" && profile.DevType != "scoreboard" {
+		return fmt.Errorf("a scoreboard driver needs a scoreboard profile, not %s", profile.DevType)
+	}
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	a.profiles[profile.Name] = profile
+	a.saveDeviceProfiles()
+	return nil
+}
+func (a *App) DeleteDeviceProfile(name string) error {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	if _, ok := a.profiles[name]; !ok {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+	delete(a.profiles, name)
+	a.saveDeviceProfiles()
+	return nil
+}
+func (a *App) GetDefaultSerialConfig() SerialConfig { return DefaultSerialConfig() }
+func (a *App) saveDeviceProfiles() {
+	if a.profilesFilePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(a.profiles, "", "  ")
+	if err != nil {
+		log.Printf("Error marshaling device profiles: %v", err)
+		return
+	}
+	if err := os.WriteFile(a.profilesFilePath, data, 0644); err != nil {
+		log.Printf("Error writing device profiles: %v", err)
+	}
+}
+func (a *App) loadDeviceProfiles() {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	data, err := os.ReadFile(a.profilesFilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading device profiles file: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &a.profiles); err != nil {
+		log.Printf("Error unmarshaling device profiles: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfileMustMatchDeviceType(t *testing.T) {
+	a := newTestApp(t)
+	if err := a.SaveDeviceProfile(DeviceProfile{Name: "gauge", DevType: "wind", Serial: DefaultSerialConfig()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, devType := range []string{"", "printer"} {
+		if err := a.SaveDeviceProfile(DeviceProfile{Name: "odd", DevType: devType, Serial: DefaultSerialConfig()}); err == nil {
+			t.Fatalf("profile for device type %q saved", devType)
+		}
+	}
+	_, err := a.ConnectSerialDeviceWithProfile("edm", "/dev/ttyUSB0", "gauge")
+	if err == nil || !strings.Contains(err.Error(), "is for a wind") {
+		t.Fatalf("wind profile applied to the EDM: %v", err)
+	}
+}
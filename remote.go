@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Remote Trigger API ---
+// An optional HTTP server on the local network that lets a tablet or a button
+// box at the landing area trigger measurements, so the EDM operator can stay
+// at the prism. Only measuring and device state are exposed, never
+// connections or calibration, and every request must carry the token.
+const (
+	remoteMeasurementEvent = "remote-measurement"
+	remoteTokenBytes       = 16
+	minRemoteTokenLength   = 8
+	remoteShutdownWait     = 2 * time.Second
+)
+
+// RemoteMeasurement is announced to the UI when a measurement was triggered
+// over HTTP.
+type RemoteMeasurement struct {
+	DeviceID string `json:"deviceId"`
+	Kind     string `json:"kind"`
+	Value    string `json:"value"`
+}
+type RemoteAPIInfo struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+type remoteServer struct {
+	server *http.Server
+	info   RemoteAPIInfo
+}
+
+// StartRemoteAPI serves the trigger API on port. With an empty token a random
+// one is generated; either way it is returned for pairing the remote device.
+func (a *App) StartRemoteAPI(port int, token string) (*RemoteAPIInfo, error) {
+	a.remoteMux.Lock()
+	defer a.remoteMux.Unlock()
+	if a.remote != nil {
+		info := a.remote.info
+		return &info, nil
+	}
+	if token == "" {
+		buf := make([]byte, remoteTokenBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("could not generate a token: %w", err)
+		}
+		token = hex.EncodeToString(buf)
+	} else if len(token) < minRemoteTokenLength {
+		return nil, fmt.Errorf("token must be at least %d characters", minRemoteTokenLength)
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("could not start remote API: %w", err)
+	}
+	srv := &remoteServer{
+		server: &http.Server{Handler: a.remoteHandler(token)},
+		info:   RemoteAPIInfo{URL: fmt.Sprintf("http://%s/", overlayHostAddress(ln.Addr().(*net.TCPAddr).Port)), Token: token},
+	}
+	go func() {
+		if err := srv.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("Remote API stopped: %v", err)
+		}
+	}()
+	a.remote = srv
+	info := srv.info
+	return &info, nil
+}
+func (a *App) StopRemoteAPI() error {
+	a.remoteMux.Lock()
+	srv := a.remote
+	a.remote = nil
+	a.remoteMux.Unlock()
+	if srv == nil {
+		return fmt.Errorf("remote API is not running")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteShutdownWait)
+	defer cancel()
+	return srv.server.Shutdown(ctx)
+}
+
+// GetRemoteAPIInfo returns nil while the remote API is stopped.
+func (a *App) GetRemoteAPIInfo() *RemoteAPIInfo {
+	a.remoteMux.Lock()
+	defer a.remoteMux.Unlock()
+	if a.remote == nil {
+		return nil
+	}
+	info := a.remote.info
+	return &info
+}
+func (a *App) remoteHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	a.measurementRoutes(mux)
+	return requireToken(token, mux)
+}
+
+// requireToken accepts the token as "Authorization: Bearer <token>" or, for
+// button boxes that cannot set headers, as a "token" query parameter.
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			given = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"missing or wrong token"}` + "\n"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"PolyField/simulator"
+)
+
+func TestRemoteAPITriggersMeasurement(t *testing.T) {
+	a, demo := newDemoApp(t)
+	cfg := simulator.DefaultEDMConfig()
+	cfg.NoiseMm = 0
+	demo.edms["edm"].SetConfig(cfg)
+	demo.throwDistance = func(string, *rand.Rand) float64 { return 12.34 }
+	if _, err := a.SetCircleCentre("edm"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := a.StartRemoteAPI(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopRemoteAPI()
+	if len(info.Token) != 2*remoteTokenBytes {
+		t.Fatalf("generated token %q", info.Token)
+	}
+	u, _ := url.Parse(info.URL)
+	base := "http://127.0.0.1:" + u.Port()
+
+	post := func(path, bearer string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("POST", base+path, nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	if resp := post("/api/v1/edm/edm/measure", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("without token: %s", resp.Status)
+	}
+	if resp := post("/api/v1/edm/edm/measure", "wrong-token"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token: %s", resp.Status)
+	}
+	resp := post("/api/v1/edm/edm/measure?token="+info.Token, "")
+	var mark markResponse
+	if err := json.NewDecoder(resp.Body).Decode(&mark); err != nil || resp.StatusCode != http.StatusOK || mark.Mark != "12.34 m" {
+		t.Fatalf("measure: %s %+v %v", resp.Status, mark, err)
+	}
+	// Connections and calibration stay with the desk.
+	if resp := post("/api/v1/devices/edm/disconnect", info.Token); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("disconnect over the remote API: %s", resp.Status)
+	}
+	if resp := post("/api/v1/edm/edm/centre", info.Token); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("calibrate over the remote API: %s", resp.Status)
+	}
+}
+
+func TestRemoteAPILifecycle(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.StartRemoteAPI(0, "short"); err == nil || !strings.Contains(err.Error(), "at least") {
+		t.Fatalf("short token accepted: %v", err)
+	}
+	first, err := a.StartRemoteAPI(0, "box-at-the-landing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := a.StartRemoteAPI(0, "another-token")
+	if err != nil || *again != *first {
+		t.Fatalf("second start = %+v, %v; want the running server %+v", again, err, first)
+	}
+	if err := a.StopRemoteAPI(); err != nil {
+		t.Fatal(err)
+	}
+	if info := a.GetRemoteAPIInfo(); info != nil {
+		t.Fatalf("info after stop = %+v", info)
+	}
+	if err := a.StopRemoteAPI(); err == nil {
+		t.Fatal("stopping a stopped API succeeded")
+	}
+}
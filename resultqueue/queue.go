@@ -0,0 +1,96 @@
+// Package resultqueue holds results the server could not take yet, persisted
+// to a JSON file so they survive a restart.
+package resultqueue
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+
+	"PolyField/api"
+)
+
+type Queue struct {
+	mu    sync.Mutex
+	items []api.ResultPayload
+	path  string
+}
+
+// New returns an empty queue saved to path; an empty path keeps it in memory.
+func New(path string) *Queue {
+	return &Queue{items: make([]api.ResultPayload, 0), path: path}
+}
+
+// Load replaces the queue with the contents of its file, if there is one.
+func (q *Queue) Load() {
+	if q.path == "" {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading result cache file: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &q.items); err != nil {
+		log.Printf("Error unmarshaling result cache: %v", err)
+	}
+}
+func (q *Queue) Add(payload api.ResultPayload) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, payload)
+	q.save()
+}
+
+// Pending returns a copy of the queued results, oldest first.
+func (q *Queue) Pending() []api.ResultPayload {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]api.ResultPayload(nil), q.items...)
+}
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Flush offers every queued result to send, in order, and keeps the ones it
+// rejects. The lock is not held while sending, so results can still be
+// queued while a slow server is being retried. It returns how many were sent.
+func (q *Queue) Flush(send func(api.ResultPayload) error) int {
+	pending := q.Pending()
+	if len(pending) == 0 {
+		return 0
+	}
+	var stillQueued []api.ResultPayload
+	for _, payload := range pending {
+		if err := send(payload); err != nil {
+			stillQueued = append(stillQueued, payload)
+		}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// Results are only ever appended, so anything past the snapshot arrived
+	// during the retry and is kept.
+	q.items = append(stillQueued, q.items[len(pending):]...)
+	q.save()
+	return len(pending) - len(stillQueued)
+}
+
+// save must be called with q.mu held.
+func (q *Queue) save() {
+	if q.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(q.items, "", "  ")
+	if err != nil {
+		log.Printf("Error marshaling result cache: %v", err)
+		return
+	}
+	os.WriteFile(q.path, data, 0644)
+}
@@ -0,0 +1,31 @@
+package resultqueue
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"PolyField/api"
+)
+
+func TestFlushKeepsRejectedResultsAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	q := New(path)
+	for _, bib := range []string{"1", "2", "3"} {
+		q.Add(api.ResultPayload{EventID: "e1", AthleteBib: bib})
+	}
+	sent := q.Flush(func(p api.ResultPayload) error {
+		if p.AthleteBib == "2" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	if sent != 2 {
+		t.Fatalf("Flush sent %d, want 2", sent)
+	}
+	reopened := New(path)
+	reopened.Load()
+	if got := reopened.Pending(); len(got) != 1 || got[0].AthleteBib != "2" {
+		t.Fatalf("reloaded queue = %+v, want only bib 2", got)
+	}
+}
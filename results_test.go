@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakePolyFieldServer is an httptest stand-in for the PolyField results
+// server. Setting status makes it reject results.
+type fakePolyFieldServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	received []ResultPayload
+	flights  map[string][]Flight
+}
+
+func newFakePolyFieldServer(t *testing.T, events []Event) *fakePolyFieldServer {
+	t.Helper()
+	f := &fakePolyFieldServer{status: http.StatusOK, flights: make(map[string][]Flight)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(events)
+	})
+	mux.HandleFunc("PUT /api/v1/events/{id}/flights", func(w http.ResponseWriter, r *http.Request) {
+		var flights []Flight
+		if err := json.NewDecoder(r.Body).Decode(&flights); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.flights[r.PathValue("id")] = flights
+	})
+	mux.HandleFunc("/api/v1/events/", func(w http.ResponseWriter, r *http.Request) {
+		for _, e := range events {
+			if r.URL.Path == "/api/v1/events/"+e.ID {
+				json.NewEncoder(w).Encode(e)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/api/v1/results", func(w http.ResponseWriter, r *http.Request) {
+		var p ResultPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.status != http.StatusOK {
+			w.WriteHeader(f.status)
+			return
+		}
+		f.received = append(f.received, p)
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+func (f *fakePolyFieldServer) hostPort() (string, int) {
+	addr := f.Listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+func (f *fakePolyFieldServer) setStatus(status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+}
+func (f *fakePolyFieldServer) eventFlights(eventID string) []Flight {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flights[eventID]
+}
+func (f *fakePolyFieldServer) results() []ResultPayload {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]ResultPayload(nil), f.received...)
+}
+
+func testResult(bib string) ResultPayload {
+	wind := "+0.4"
+	return ResultPayload{EventID: "sp-m", AthleteBib: bib, Series: []Performance{{Attempt: 1, Mark: "12.34", Unit: "m", Wind: &wind, Valid: true}, {Attempt: 2, Mark: "FOUL", Unit: "m"}}}
+}
+
+func TestFetchEvents(t *testing.T) {
+	events := []Event{{ID: "sp-m", Name: "Shot Put Men", Type: "Throws", Athletes: []Athlete{{Bib: "1", Order: 1, Name: "A"}}}, {ID: "lj-w", Name: "Long Jump Women", Type: "Horizontal Jumps"}}
+	srv := newFakePolyFieldServer(t, events)
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	got, err := a.FetchEvents(host, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].ID != "lj-w" {
+		t.Fatalf("FetchEvents = %+v", got)
+	}
+	detail, err := a.FetchEventDetails(host, port, "sp-m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*detail, events[0]) {
+		t.Fatalf("FetchEventDetails = %+v, want %+v", *detail, events[0])
+	}
+	if _, err := a.FetchEventDetails(host, port, "missing"); err == nil {
+		t.Fatal("expected an error for an unknown event")
+	}
+}
+
+func TestPostResultDelivers(t *testing.T) {
+	srv := newFakePolyFieldServer(t, nil)
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	if err := a.PostResult(host, port, testResult("7")); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.results(); len(got) != 1 || !reflect.DeepEqual(got[0], testResult("7")) {
+		t.Fatalf("server received %+v", got)
+	}
+	if c := a.results.Pending(); len(c) != 0 {
+		t.Fatalf("cache = %+v, want empty", c)
+	}
+}
+
+// The UI posts the whole series, with rounds still to come as placeholders.
+func TestPostResultAcceptsPlaceholderRounds(t *testing.T) {
+	srv := newFakePolyFieldServer(t, nil)
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	payload := ResultPayload{EventID: "sp-m", AthleteBib: "7", Series: []Performance{
+		{Attempt: 1, Mark: "12.34", Unit: "m", Valid: true},
+		{Attempt: 2, Mark: "", Unit: "m", Valid: true},
+		{Attempt: 3, Mark: "", Unit: "m", Valid: true},
+	}}
+	if err := a.PostResult(host, port, payload); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.results(); len(got) != 1 || len(got[0].Series) != 3 {
+		t.Fatalf("server received %+v", got)
+	}
+	if got := a.GetStandings("sp-m"); len(got) != 1 || got[0].Best != "12.34" {
+		t.Fatalf("standings %+v", got)
+	}
+}
+
+func TestPostResultCachesOnServerError(t *testing.T) {
+	srv := newFakePolyFieldServer(t, nil)
+	srv.setStatus(http.StatusServiceUnavailable)
+	a := NewApp()
+	cachePath := filepath.Join(t.TempDir(), cacheFileName)
+	openResultCache(a, cachePath)
+	host, port := srv.hostPort()
+	if err := a.PostResult(host, port, testResult("7")); err == nil {
+		t.Fatal("expected an error when the server rejects the result")
+	}
+	if c := a.results.Pending(); len(c) != 1 {
+		t.Fatalf("cache = %+v, want the rejected result", c)
+	}
+
+	// The cache survives a restart.
+	restarted := NewApp()
+	openResultCache(restarted, cachePath)
+	if c := restarted.results.Pending(); len(c) != 1 || !reflect.DeepEqual(c[0], testResult("7")) {
+		t.Fatalf("reloaded cache = %+v", c)
+	}
+
+	// Once the server recovers, the retry delivers it and empties the cache.
+	srv.setStatus(http.StatusOK)
+	restarted.SetServerAddress(host, port)
+	restarted.flushResultCache()
+	if c := restarted.results.Pending(); len(c) != 0 {
+		t.Fatalf("cache after flush = %+v, want empty", c)
+	}
+	if got := srv.results(); len(got) != 1 || got[0].AthleteBib != "7" {
+		t.Fatalf("server received %+v", got)
+	}
+	reloaded := NewApp()
+	openResultCache(reloaded, cachePath)
+	if c := reloaded.results.Pending(); len(c) != 0 {
+		t.Fatalf("cache file after flush still holds %+v", c)
+	}
+}
+
+func TestPostResultCachesWhenServerUnreachable(t *testing.T) {
+	srv := newFakePolyFieldServer(t, nil)
+	host, port := srv.hostPort()
+	srv.Close()
+	a := newTestApp(t)
+	for _, bib := range []string{"1", "2", "3"} {
+		if err := a.PostResult(host, port, testResult(bib)); err == nil {
+			t.Fatal("expected a network error")
+		}
+	}
+	a.SetServerAddress(host, port)
+	a.flushResultCache()
+	c := a.results.Pending()
+	if len(c) != 3 || c[0].AthleteBib != "1" || c[2].AthleteBib != "3" {
+		t.Fatalf("cache after failed flush = %+v, want all three in order", c)
+	}
+}
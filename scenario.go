@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"PolyField/devices"
+	"PolyField/simulator"
+)
+
+// --- Demo Scenarios ---
+// A scenario scripts a whole training competition. The same file and the same
+// actions by the official always produce the same readings, so sessions can
+// be repeated and bug reports replayed.
+const (
+	demoScenarioEvent   = "demo-scenario-step"
+	demoWindFlushMargin = 100 * time.Millisecond
+)
+
+type DemoScenario struct {
+	Name  string               `json:"name"`
+	Seed  int64                `json:"seed"`
+	Event Event                `json:"event"`
+	EDM   *simulator.EDMConfig `json:"edm,omitempty"`
+	Steps []DemoScenarioStep   `json:"steps"`
+}
+
+// DemoScenarioStep is one attempt. Mark is metres beyond the circle; a foul
+// without a mark lands outside the sector at a seeded random distance. The
+// gauge reports Wind steadily, calm when it is not set, so the reading does
+// not depend on when it is taken. Dropout lists devices that stop answering
+// for the duration of the step.
+type DemoScenarioStep struct {
+	Bib     string   `json:"bib"`
+	Attempt int      `json:"attempt"`
+	Mark    float64  `json:"mark,omitempty"`
+	Foul    bool     `json:"foul,omitempty"`
+	Wind    *float64 `json:"wind,omitempty"`
+	Dropout []string `json:"dropout,omitempty"`
+}
+type DemoScenarioStatus struct {
+	Name    string            `json:"name"`
+	Step    int               `json:"step"`
+	Total   int               `json:"total"`
+	Current *DemoScenarioStep `json:"current,omitempty"`
+	Athlete *Athlete          `json:"athlete,omitempty"`
+	Done    bool              `json:"done"`
+}
+
+func (s *DemoScenario) validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("scenario has no steps")
+	}
+	bibs := make(map[string]bool, len(s.Event.Athletes))
+	for _, ath := range s.Event.Athletes {
+		if bibs[ath.Bib] {
+			return fmt.Errorf("athlete bib %s is listed twice", ath.Bib)
+		}
+		bibs[ath.Bib] = true
+	}
+	for i, step := range s.Steps {
+		if !bibs[step.Bib] {
+			return fmt.Errorf("step %d: bib %s is not in the event", i+1, step.Bib)
+		}
+		if step.Mark < 0 || (step.Mark == 0 && !step.Foul) {
+			return fmt.Errorf("step %d: a valid attempt needs a positive mark", i+1)
+		}
+		for _, id := range step.Dropout {
+			if _, err := devices.TypeForID(id); err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadDemoScenario registers the scenario's event, switches to demo mode with
+// fresh simulators seeded from the scenario and moves to its first step.
+func (a *App) LoadDemoScenario(path string) (*DemoScenarioStatus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var scenario DemoScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario file: %w", err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+	if err := a.registerEvent(scenario.Event); err != nil {
+		return nil, err
+	}
+	a.stopDemoHardware()
+	a.stateMux.Lock()
+	a.demoMode = true
+	a.stateMux.Unlock()
+	demo := newDemoHardware(scenario.Seed)
+	if scenario.EDM != nil {
+		demo.edmConfig = *scenario.EDM
+	}
+	demo.windConfig.Speed, demo.windConfig.Gust = 0, 0
+	demo.scenario = &scenario
+	demo.throwDistance = demo.scenarioThrowDistance
+	a.startDemoHardware(demo)
+	a.SetOverlayEvent(scenario.Event)
+	return a.applyScenarioStep(demo)
+}
+func (a *App) GetDemoScenarioStatus() (*DemoScenarioStatus, error) {
+	demo := a.demoHardware()
+	if demo == nil || demo.scenario == nil {
+		return nil, fmt.Errorf("no demo scenario is loaded")
+	}
+	demo.mu.Lock()
+	defer demo.mu.Unlock()
+	return demo.scenarioStatus(), nil
+}
+
+// NextDemoScenarioStep moves the script on to the next attempt.
+func (a *App) NextDemoScenarioStep() (*DemoScenarioStatus, error) {
+	demo := a.demoHardware()
+	if demo == nil || demo.scenario == nil {
+		return nil, fmt.Errorf("no demo scenario is loaded")
+	}
+	demo.mu.Lock()
+	if demo.step < len(demo.scenario.Steps) {
+		demo.step++
+	}
+	demo.mu.Unlock()
+	return a.applyScenarioStep(demo)
+}
+
+// applyScenarioStep sets the simulated wind and dropouts for the current step
+// and shows the athlete on the overlay. The step's wind is then the only wind
+// the gauges have reported.
+func (a *App) applyScenarioStep(demo *demoHardware) (*DemoScenarioStatus, error) {
+	demo.mu.Lock()
+	status := demo.scenarioStatus()
+	dropped := make(map[string]bool)
+	if status.Current != nil {
+		for _, id := range status.Current.Dropout {
+			dropped[id] = true
+		}
+	}
+	for id, sim := range demo.edms {
+		cfg := demo.edmConfig
+		if dropped[id] {
+			cfg.DropoutRate = 1
+		}
+		sim.SetConfig(cfg)
+	}
+	var gauges []string
+	for id, sim := range demo.winds {
+		gauges = append(gauges, id)
+		cfg := demo.windConfig
+		if status.Current != nil && status.Current.Wind != nil {
+			cfg.Speed = *status.Current.Wind
+		}
+		if dropped[id] {
+			cfg.DropoutRate = 1
+		}
+		sim.SetConfig(cfg)
+	}
+	interval := demo.windConfig.Interval
+	demo.mu.Unlock()
+	a.flushDemoWind(gauges, interval)
+	if status.Current != nil {
+		a.SetOverlayAthlete(status.Current.Bib, status.Current.Attempt)
+	}
+	a.emitEvent(demoScenarioEvent, status)
+	return status, nil
+}
+
+// flushDemoWind waits for any sample the gauges made before the step's wind
+// was set, then drops everything they have reported.
+func (a *App) flushDemoWind(gauges []string, interval time.Duration) {
+	if len(gauges) == 0 {
+		return
+	}
+	time.Sleep(interval + demoWindFlushMargin)
+	a.windMux.Lock()
+	defer a.windMux.Unlock()
+	for _, id := range gauges {
+		delete(a.windBuffers, id)
+	}
+}
+
+// scenarioStatus must be called with d.mu held.
+func (d *demoHardware) scenarioStatus() *DemoScenarioStatus {
+	status := &DemoScenarioStatus{Name: d.scenario.Name, Step: d.step + 1, Total: len(d.scenario.Steps)}
+	if d.step >= len(d.scenario.Steps) {
+		status.Step, status.Done = len(d.scenario.Steps), true
+		return status
+	}
+	step := d.scenario.Steps[d.step]
+	status.Current = &step
+	for _, ath := range d.scenario.Event.Athletes {
+		if ath.Bib == step.Bib {
+			found := ath
+			status.Athlete = &found
+		}
+	}
+	return status
+}
+
+// currentStep must be called with d.mu held. It is nil outside a scenario
+// and once it is done.
+func (d *demoHardware) currentStep() *DemoScenarioStep {
+	if d.scenario == nil || d.step >= len(d.scenario.Steps) {
+		return nil
+	}
+	return &d.scenario.Steps[d.step]
+}
+
+// scenarioThrowDistance must be called with d.mu held.
+func (d *demoHardware) scenarioThrowDistance(circleType string, rng *rand.Rand) float64 {
+	if step := d.currentStep(); step != nil && step.Mark > 0 {
+		return step.Mark
+	}
+	return d.randomThrowDistance(circleType, rng)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"PolyField/devices"
+)
+
+// --- Scoreboard Protocol Drivers ---
+const (
+	ScoreboardGenericASCII = devices.ScoreboardGenericASCII
+	ScoreboardALGE         = devices.ScoreboardALGE
+	ScoreboardDaktronics   = devices.ScoreboardDaktronics
+)
+
+type ScoreboardMessage = devices.ScoreboardMessage
+type ScoreboardDriver = devices.ScoreboardDriver
+
+func (a *App) ListScoreboardDrivers() []string { return devices.ScoreboardDriverNames() }
+
+// SetScoreboardDriver may be called before the scoreboard is connected; the
+// choice is kept per device name.
+func (a *App) SetScoreboardDriver(deviceID, driver string) error {
+	if devType, err := devices.TypeForID(deviceID); err != nil || devType != "scoreboard" {
+		return fmt.Errorf("'%s' is not a scoreboard", deviceID)
+	}
+	if _, ok := devices.ScoreboardDrivers[driver]; !ok {
+		return fmt.Errorf("unknown scoreboard driver '%s'", driver)
+	}
+	a.devicesMux.Lock()
+	defer a.devicesMux.Unlock()
+	a.scoreboardProtocols[deviceID] = driver
+	return nil
+}
+func (a *App) scoreboardDriverFor(deviceID string) ScoreboardDriver {
+	a.devicesMux.RLock()
+	defer a.devicesMux.RUnlock()
+	return devices.ScoreboardDriverFor(a.scoreboardProtocols[deviceID])
+}
+func (a *App) SendScoreboardMessage(deviceID string, msg ScoreboardMessage) error {
+	return a.writeScoreboard(deviceID, func(d ScoreboardDriver, term string) ([]byte, error) {
+		return d.Format(msg, term), nil
+	})
+}
+func (a *App) SetScoreboardBrightness(deviceID string, level int) error {
+	return a.writeScoreboard(deviceID, func(d ScoreboardDriver, term string) ([]byte, error) {
+		return d.Brightness(level, term)
+	})
+}
+func (a *App) ClearScoreboard(deviceID string) error {
+	return a.writeScoreboard(deviceID, func(d ScoreboardDriver, term string) ([]byte, error) {
+		return d.Clear(term), nil
+	})
+}
+func (a *App) writeScoreboard(deviceID string, build func(d ScoreboardDriver, terminator string) ([]byte, error)) error {
+	driver := a.scoreboardDriverFor(deviceID)
+	if _, err := a.demoDevice(deviceID); err != nil {
+		return err
+	}
+	scoreboard, ok := a.device(deviceID)
+	if !ok {
+		return fmt.Errorf("scoreboard '%s' not connected", deviceID)
+	}
+	payload, err := build(driver, scoreboard.WriteTerm())
+	if err != nil {
+		return err
+	}
+	return scoreboard.Send(payload)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"PolyField/devices"
+)
+
+// --- Scoreboard Outbound Queue ---
+const (
+	scoreboardRetryAttempts  = 3
+	scoreboardRetryDelay     = 500 * time.Millisecond
+	scoreboardDeliveryEvent  = "scoreboard-delivery"
+	scoreboardDeliveryMemory = 100
+	DeliveryQueued           = "queued"
+	DeliverySent             = "sent"
+	DeliveryFailed           = "failed"
+	DeliverySuperseded       = "superseded"
+	ScoreboardKindMark       = "mark"
+	ScoreboardKindWind       = "wind"
+	ScoreboardKindStandings  = "standings"
+	ScoreboardKindClock      = "clock"
+	ScoreboardKindDisplay    = "display"
+	ScoreboardKindTest       = "test"
+)
+
+type ScoreboardDelivery struct {
+	ID           int64             `json:"id"`
+	ScoreboardID string            `json:"scoreboardId"`
+	Kind         string            `json:"kind"`
+	Status       string            `json:"status"`
+	Attempts     int               `json:"attempts"`
+	Error        string            `json:"error,omitempty"`
+	Message      ScoreboardMessage `json:"message"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// ScoreboardStep is one screen of a timed sequence, e.g. the mark for ten
+// seconds followed by the standings.
+type ScoreboardStep struct {
+	Message     ScoreboardMessage `json:"message"`
+	HoldSeconds float64           `json:"holdSeconds"`
+}
+
+type queuedScoreboardMessage struct {
+	delivery *ScoreboardDelivery
+	hold     time.Duration
+}
+
+// scoreboardQueue delivers messages to one scoreboard strictly in the order
+// they were queued. A message that is still waiting when a newer one of the
+// same kind arrives is stale and is dropped, and a new message of the kind
+// being held cuts the hold short. Timed sequences are of the display kind, so
+// a clock tick or a mark queued meanwhile waits for the sequence to finish.
+type scoreboardQueue struct {
+	mu         sync.Mutex
+	pending    []*queuedScoreboardMessage
+	wake       chan struct{}
+	interrupt  chan struct{}
+	holding    string // kind of the message being held, if any
+	deliveries []*ScoreboardDelivery
+}
+
+func newScoreboardQueue() *scoreboardQueue {
+	return &scoreboardQueue{wake: make(chan struct{}, 1), interrupt: make(chan struct{}, 1)}
+}
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (a *App) scoreboardQueueFor(deviceID string) *scoreboardQueue {
+	a.devicesMux.Lock()
+	defer a.devicesMux.Unlock()
+	q, ok := a.scoreboardQueues[deviceID]
+	if !ok {
+		q = newScoreboardQueue()
+		a.scoreboardQueues[deviceID] = q
+		go a.runScoreboardQueue(deviceID, q)
+	}
+	return q
+}
+func (a *App) QueueScoreboardMessage(deviceID, kind string, msg ScoreboardMessage) (int64, error) {
+	ids, err := a.enqueueScoreboard(deviceID, kind, []ScoreboardStep{{Message: msg}})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+func (a *App) QueueScoreboardSequence(deviceID string, steps []ScoreboardStep) ([]int64, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("sequence has no steps")
+	}
+	return a.enqueueScoreboard(deviceID, ScoreboardKindDisplay, steps)
+}
+func (a *App) enqueueScoreboard(deviceID, kind string, steps []ScoreboardStep) ([]int64, error) {
+	if devType, err := devices.TypeForID(deviceID); err != nil || devType != "scoreboard" {
+		return nil, fmt.Errorf("'%s' is not a scoreboard", deviceID)
+	}
+	if kind == "" {
+		kind = ScoreboardKindDisplay
+	}
+	q := a.scoreboardQueueFor(deviceID)
+	q.mu.Lock()
+	var superseded []ScoreboardDelivery
+	kept := q.pending[:0]
+	for _, item := range q.pending {
+		if item.delivery.Kind == kind {
+			item.delivery.Status = DeliverySuperseded
+			item.delivery.UpdatedAt = time.Now()
+			superseded = append(superseded, *item.delivery)
+			continue
+		}
+		kept = append(kept, item)
+	}
+	q.pending = kept
+	interrupt := q.holding == kind
+	ids := make([]int64, 0, len(steps))
+	var queued []ScoreboardDelivery
+	for _, step := range steps {
+		d := &ScoreboardDelivery{ID: a.nextScoreboardID(), ScoreboardID: deviceID, Kind: kind, Status: DeliveryQueued, Message: step.Message, UpdatedAt: time.Now()}
+		q.pending = append(q.pending, &queuedScoreboardMessage{delivery: d, hold: time.Duration(step.HoldSeconds * float64(time.Second))})
+		q.remember(d)
+		ids = append(ids, d.ID)
+		queued = append(queued, *d)
+	}
+	q.mu.Unlock()
+	for _, d := range append(superseded, queued...) {
+		a.emitEvent(scoreboardDeliveryEvent, d)
+	}
+	if interrupt {
+		signal(q.interrupt)
+	}
+	signal(q.wake)
+	return ids, nil
+}
+func (a *App) nextScoreboardID() int64 {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	a.scoreboardSeq++
+	return a.scoreboardSeq
+}
+
+// remember keeps recent deliveries for GetScoreboardDeliveries. Callers must
+// hold q.mu.
+func (q *scoreboardQueue) remember(d *ScoreboardDelivery) {
+	q.deliveries = append(q.deliveries, d)
+	if len(q.deliveries) > scoreboardDeliveryMemory {
+		q.deliveries = q.deliveries[len(q.deliveries)-scoreboardDeliveryMemory:]
+	}
+}
+func (q *scoreboardQueue) next() *queuedScoreboardMessage {
+	for {
+		q.mu.Lock()
+		if len(q.pending) > 0 {
+			item := q.pending[0]
+			q.pending = q.pending[1:]
+			if item.hold > 0 {
+				q.holding = item.delivery.Kind
+			}
+			q.mu.Unlock()
+			return item
+		}
+		q.mu.Unlock()
+		<-q.wake
+	}
+}
+func (q *scoreboardQueue) endHold() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.holding = ""
+}
+func (q *scoreboardQueue) update(d *ScoreboardDelivery, status string, attempts int, err error) ScoreboardDelivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d.Status = status
+	d.Attempts = attempts
+	d.Error = ""
+	if err != nil {
+		d.Error = err.Error()
+	}
+	d.UpdatedAt = time.Now()
+	return *d
+}
+func (a *App) runScoreboardQueue(deviceID string, q *scoreboardQueue) {
+	for {
+		item := q.next()
+		// Drain any interrupt raised before this item was taken, so it only
+		// cuts short holds for messages queued after it.
+		select {
+		case <-q.interrupt:
+		default:
+		}
+		var err error
+		attempts := 0
+		for attempts < scoreboardRetryAttempts {
+			attempts++
+			if err = a.SendScoreboardMessage(deviceID, item.delivery.Message); err == nil {
+				break
+			}
+			if attempts < scoreboardRetryAttempts {
+				time.Sleep(time.Duration(attempts) * scoreboardRetryDelay)
+			}
+		}
+		status := DeliverySent
+		if err != nil {
+			status = DeliveryFailed
+		}
+		a.emitEvent(scoreboardDeliveryEvent, q.update(item.delivery, status, attempts, err))
+		if item.hold > 0 && err == nil {
+			select {
+			case <-time.After(item.hold):
+			case <-q.interrupt:
+			}
+		}
+		q.endHold()
+	}
+}
+func (a *App) GetScoreboardDeliveries(deviceID string) []ScoreboardDelivery {
+	a.devicesMux.RLock()
+	q, ok := a.scoreboardQueues[deviceID]
+	a.devicesMux.RUnlock()
+	if !ok {
+		return []ScoreboardDelivery{}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	deliveries := make([]ScoreboardDelivery, 0, len(q.deliveries))
+	for _, d := range q.deliveries {
+		deliveries = append(deliveries, *d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	return deliveries
+}
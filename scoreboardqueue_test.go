@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// awaitDelivery waits for a delivery to leave the queue and returns it.
+func awaitDelivery(t *testing.T, a *App, id int64) ScoreboardDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, d := range a.GetScoreboardDeliveries("scoreboard") {
+			if d.ID == id && d.Status != DeliveryQueued {
+				return d
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("delivery %d still queued", id)
+	return ScoreboardDelivery{}
+}
+
+// With no scoreboard connected every send fails, so the first message is
+// retried while later ones wait in the queue.
+func TestNewerMessageSupersedesQueuedOne(t *testing.T) {
+	a := newTestApp(t)
+	var ids []int64
+	for _, mark := range []string{"10.00", "11.00", "12.00"} {
+		id, err := a.QueueScoreboardMessage("scoreboard", ScoreboardKindMark, ScoreboardMessage{Lines: []string{mark}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		time.Sleep(20 * time.Millisecond)
+	}
+	if d := awaitDelivery(t, a, ids[1]); d.Status != DeliverySuperseded {
+		t.Fatalf("stale mark %s, want superseded", d.Status)
+	}
+	d := awaitDelivery(t, a, ids[2])
+	if d.Status != DeliveryFailed || d.Attempts != scoreboardRetryAttempts {
+		t.Fatalf("latest mark %+v, want failed after %d attempts", d, scoreboardRetryAttempts)
+	}
+}
+
+func TestNewDisplayDropsQueuedSequence(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.QueueScoreboardMessage("scoreboard", ScoreboardKindMark, ScoreboardMessage{Lines: []string{"10.00"}}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	ids, err := a.QueueScoreboardSequence("scoreboard", []ScoreboardStep{
+		{Message: ScoreboardMessage{Lines: []string{"12.34"}}, HoldSeconds: 10},
+		{Message: ScoreboardMessage{Lines: []string{"1 Ames 12.34"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.QueueScoreboardMessage("scoreboard", ScoreboardKindDisplay, ScoreboardMessage{Lines: []string{"BREAK"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if d := awaitDelivery(t, a, id); d.Status != DeliverySuperseded {
+			t.Fatalf("sequence step %d %s, want superseded", id, d.Status)
+		}
+	}
+}
+
+func TestClockTicksWaitForSequence(t *testing.T) {
+	a, _ := newDemoApp(t)
+	hold := 400 * time.Millisecond
+	ids, err := a.QueueScoreboardSequence("scoreboard", []ScoreboardStep{
+		{Message: ScoreboardMessage{Lines: []string{"12.34"}}, HoldSeconds: hold.Seconds()},
+		{Message: ScoreboardMessage{Lines: []string{"1 Ames 12.34"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids[1] != ids[0]+1 {
+		t.Fatalf("sequence IDs %v have a gap", ids)
+	}
+	first := awaitDelivery(t, a, ids[0])
+	for i := 0; i < 5; i++ {
+		if _, err := a.QueueScoreboardMessage("scoreboard", ScoreboardKindClock, ScoreboardMessage{Lines: []string{"0:59"}}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	second := awaitDelivery(t, a, ids[1])
+	if second.Status != DeliverySent {
+		t.Fatalf("standings step %s by clock ticks", second.Status)
+	}
+	if held := second.UpdatedAt.Sub(first.UpdatedAt); held < hold-50*time.Millisecond {
+		t.Fatalf("mark held for %v, want %v", held, hold)
+	}
+}
+
+func TestNewDisplayCutsSequenceShort(t *testing.T) {
+	a, _ := newDemoApp(t)
+	ids, err := a.QueueScoreboardSequence("scoreboard", []ScoreboardStep{
+		{Message: ScoreboardMessage{Lines: []string{"12.34"}}, HoldSeconds: 10},
+		{Message: ScoreboardMessage{Lines: []string{"1 Ames 12.34"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	awaitDelivery(t, a, ids[0])
+	id, err := a.QueueScoreboardMessage("scoreboard", ScoreboardKindDisplay, ScoreboardMessage{Lines: []string{"BREAK"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := awaitDelivery(t, a, id); d.Status != DeliverySent {
+		t.Fatalf("new display %+v", d)
+	}
+	if d := awaitDelivery(t, a, ids[1]); d.Status != DeliverySuperseded {
+		t.Fatalf("rest of the sequence %s, want superseded", d.Status)
+	}
+}
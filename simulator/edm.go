@@ -0,0 +1,119 @@
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// readCommand is the byte the EDM answers; the real instrument ignores the
+// CR/LF that follows it.
+const readCommand = 0x11
+
+// EDMConfig places the instrument relative to the circle centre (metres, same
+// axes as PolyField's calibration) and describes how badly it behaves.
+type EDMConfig struct {
+	StationX         float64 `json:"stationX"`
+	StationY         float64 `json:"stationY"`
+	InstrumentHeight float64 `json:"instrumentHeight"`
+	NoiseMm          float64 `json:"noiseMm"`
+	DropoutRate      float64 `json:"dropoutRate,omitempty"`
+	MalformedRate    float64 `json:"malformedRate,omitempty"`
+}
+
+// DefaultEDMConfig is a tripod 1.5 m high, 4 m behind and 3 m to the side of
+// the circle, with realistic sub-millimetre noise and no faults.
+func DefaultEDMConfig() EDMConfig {
+	return EDMConfig{StationX: -4, StationY: -3, InstrumentHeight: 1.5, NoiseMm: 0.5}
+}
+
+// EDM answers read commands with slope distance, zenith angle and horizontal
+// angle to wherever the prism is currently held.
+type EDM struct {
+	mu  sync.Mutex
+	cfg EDMConfig
+	aim [2]float64
+	rng *lockedRand
+}
+
+func NewEDM(cfg EDMConfig, rng *rand.Rand) *EDM {
+	return &EDM{cfg: cfg, rng: newLockedRand(rng)}
+}
+
+// Aim moves the prism to (x, y) metres from the circle centre.
+func (e *EDM) Aim(x, y float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.aim = [2]float64{x, y}
+}
+func (e *EDM) SetConfig(cfg EDMConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cfg = cfg
+}
+func (e *EDM) Config() EDMConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cfg
+}
+
+func (e *EDM) Serve(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		if b != readCommand {
+			continue
+		}
+		resp, ok := e.respond()
+		if !ok {
+			logf("EDM dropped a reading")
+			continue
+		}
+		if _, err := io.WriteString(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+// respond builds the reply to one read command, or reports a dropout.
+func (e *EDM) respond() (string, bool) {
+	e.mu.Lock()
+	cfg, aim := e.cfg, e.aim
+	e.mu.Unlock()
+	if cfg.DropoutRate > 0 && e.rng.Float64() < cfg.DropoutRate {
+		return "", false
+	}
+	if cfg.MalformedRate > 0 && e.rng.Float64() < cfg.MalformedRate {
+		return "E105 ??\r\n", true
+	}
+	sdMm, vaz, har := Observe(cfg, aim[0], aim[1])
+	sdMm += e.rng.NormFloat64() * cfg.NoiseMm
+	return fmt.Sprintf("%.0f %s %s 83\r\n", sdMm, FormatDDDMMSS(vaz), FormatDDDMMSS(har)), true
+}
+
+// Observe returns the slope distance (mm), zenith angle and horizontal angle
+// (degrees) that an ideal instrument at the configured station reads for a
+// prism on the ground at (x, y).
+func Observe(cfg EDMConfig, x, y float64) (sdMm, vazDeg, harDeg float64) {
+	dx, dy := x-cfg.StationX, y-cfg.StationY
+	hd := math.Hypot(dx, dy)
+	sd := math.Hypot(hd, cfg.InstrumentHeight)
+	vazDeg = math.Atan2(hd, -cfg.InstrumentHeight) * 180 / math.Pi
+	harDeg = math.Mod(math.Atan2(dy, dx)*180/math.Pi+360, 360)
+	return sd * 1000, vazDeg, harDeg
+}
+
+// FormatDDDMMSS renders an angle the way the EDM does: degrees, minutes and
+// seconds packed into seven digits.
+func FormatDDDMMSS(deg float64) string {
+	total := int(math.Round(deg * 3600))
+	total %= 360 * 3600
+	return fmt.Sprintf("%03d%02d%02d", total/3600, total/60%60, total%60)
+}
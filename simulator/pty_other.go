@@ -0,0 +1,12 @@
+//go:build !linux
+
+package simulator
+
+import (
+	"errors"
+	"os"
+)
+
+func openPTY() (*os.File, string, error) {
+	return nil, "", errors.New("pseudo-terminal simulation is only supported on Linux; use ListenTCP")
+}
@@ -0,0 +1,46 @@
+package simulator
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+const scoreboardHistory = 50
+
+// Scoreboard accepts whatever is written to it and remembers the most recent
+// payloads, one entry per write.
+type Scoreboard struct {
+	mu       sync.Mutex
+	received []string
+}
+
+func NewScoreboard() *Scoreboard { return &Scoreboard{} }
+
+func (s *Scoreboard) Serve(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			payload := string(buf[:n])
+			logf("scoreboard shows %q", strings.TrimSpace(payload))
+			s.mu.Lock()
+			s.received = append(s.received, payload)
+			if len(s.received) > scoreboardHistory {
+				s.received = s.received[1:]
+			}
+			s.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Received returns the payloads written so far, oldest first.
+func (s *Scoreboard) Received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
@@ -0,0 +1,83 @@
+// Package simulator emulates PolyField's field hardware over the same wire
+// protocols the real devices use, so that demo mode and tests exercise the
+// full parsing, consistency and geometry code rather than canned values.
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+)
+
+// Handler serves one connection to a simulated device until it is closed.
+type Handler interface {
+	Serve(conn io.ReadWriteCloser)
+}
+
+// Endpoint is a running transport for a simulated device.
+type Endpoint struct {
+	// Address is "host:port" for TCP endpoints or the device path for PTYs.
+	Address string
+	closer  io.Closer
+}
+
+func (e *Endpoint) Close() error { return e.closer.Close() }
+
+// ListenTCP serves h on addr (use "127.0.0.1:0" for a free port). Each
+// accepted connection is served on its own goroutine.
+func ListenTCP(h Handler, addr string) (*Endpoint, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("simulator listen: %w", err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go h.Serve(conn)
+		}
+	}()
+	return &Endpoint{Address: ln.Addr().String(), closer: ln}, nil
+}
+
+// ServePTY serves h on a new pseudo-terminal and returns the path of its
+// slave side, which can be opened like any serial port.
+func ServePTY(h Handler) (*Endpoint, error) {
+	master, path, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	go h.Serve(master)
+	return &Endpoint{Address: path, closer: master}, nil
+}
+
+// lockedRand makes a *rand.Rand safe to share between connections while
+// keeping its sequence deterministic for a given seed.
+type lockedRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newLockedRand(rng *rand.Rand) *lockedRand {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	return &lockedRand{rng: rng}
+}
+func (r *lockedRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+func (r *lockedRand) NormFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.NormFloat64()
+}
+
+func logf(format string, args ...interface{}) { log.Printf("SIM: "+format, args...) }
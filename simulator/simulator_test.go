@@ -0,0 +1,96 @@
+package simulator
+
+import (
+	"bufio"
+	"math"
+	"math/rand"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestObserveRoundTrip(t *testing.T) {
+	cfg := EDMConfig{StationX: -4, StationY: -3, InstrumentHeight: 1.5}
+	sdMm, vaz, har := Observe(cfg, 20, 5)
+	hd := sdMm / 1000 * math.Sin(vaz*math.Pi/180)
+	x := cfg.StationX + hd*math.Cos(har*math.Pi/180)
+	y := cfg.StationY + hd*math.Sin(har*math.Pi/180)
+	if math.Abs(x-20) > 1e-9 || math.Abs(y-5) > 1e-9 {
+		t.Fatalf("reconstructed (%v, %v), want (20, 5)", x, y)
+	}
+}
+
+func TestFormatDDDMMSS(t *testing.T) {
+	for deg, want := range map[float64]string{0: "0000000", 92.5: "0923000", 359.99999: "0000000", 45.0125: "0450045"} {
+		if got := FormatDDDMMSS(deg); got != want {
+			t.Errorf("FormatDDDMMSS(%v) = %q, want %q", deg, got, want)
+		}
+	}
+}
+
+func readEDM(t *testing.T, conn net.Conn) []string {
+	t.Helper()
+	if _, err := conn.Write([]byte{readCommand, '\r', '\n'}); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(line)
+}
+
+func TestEDMOverTCP(t *testing.T) {
+	edm := NewEDM(EDMConfig{InstrumentHeight: 1.5}, rand.New(rand.NewSource(1)))
+	edm.Aim(10, 0)
+	ep, err := ListenTCP(edm, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ep.Close()
+	conn, err := net.Dial("tcp", ep.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fields := readEDM(t, conn)
+	if len(fields) != 4 {
+		t.Fatalf("reply %q", fields)
+	}
+	if sd, _ := strconv.ParseFloat(fields[0], 64); math.Abs(sd-math.Hypot(10, 1.5)*1000) > 1 {
+		t.Fatalf("slope distance %v", sd)
+	}
+	if fields[2] != "0000000" {
+		t.Fatalf("horizontal angle %q, want 0000000", fields[2])
+	}
+}
+
+func TestEDMOverPTY(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("PTYs are only supported on Linux")
+	}
+	edm := NewEDM(EDMConfig{InstrumentHeight: 1.5}, nil)
+	ep, err := ServePTY(edm)
+	if err != nil {
+		t.Skipf("no PTY available: %v", err)
+	}
+	defer ep.Close()
+	f, err := os.OpenFile(ep.Address, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte{readCommand, '\r', '\n'}); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strings.Fields(line)) != 4 {
+		t.Fatalf("reply %q", line)
+	}
+}
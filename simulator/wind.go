@@ -0,0 +1,68 @@
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// WindConfig describes the wind the gauge reports. Each sample is Speed plus
+// uniform gusting of up to ±Gust; Interval is the reporting period.
+type WindConfig struct {
+	Speed         float64       `json:"speed"`
+	Gust          float64       `json:"gust"`
+	Interval      time.Duration `json:"-"`
+	DropoutRate   float64       `json:"dropoutRate,omitempty"`
+	MalformedRate float64       `json:"malformedRate,omitempty"`
+}
+
+func DefaultWindConfig() WindConfig {
+	return WindConfig{Speed: 0.8, Gust: 1.2, Interval: 200 * time.Millisecond}
+}
+
+// Wind streams "WS,+1.23,M" records, the same shape as the gauges PolyField
+// reads, to every connection until it is closed.
+type Wind struct {
+	mu  sync.Mutex
+	cfg WindConfig
+	rng *lockedRand
+}
+
+func NewWind(cfg WindConfig, rng *rand.Rand) *Wind {
+	return &Wind{cfg: cfg, rng: newLockedRand(rng)}
+}
+func (w *Wind) SetConfig(cfg WindConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cfg = cfg
+}
+func (w *Wind) Config() WindConfig {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cfg
+}
+
+func (w *Wind) Serve(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	for {
+		interval := w.Config().Interval
+		if interval <= 0 {
+			interval = DefaultWindConfig().Interval
+		}
+		time.Sleep(interval)
+		// Each sample is made from the configuration when it is sent.
+		cfg := w.Config()
+		if cfg.DropoutRate > 0 && w.rng.Float64() < cfg.DropoutRate {
+			continue
+		}
+		line := fmt.Sprintf("WS,%+.2f,M\r\n", cfg.Speed+(w.rng.Float64()*2-1)*cfg.Gust)
+		if cfg.MalformedRate > 0 && w.rng.Float64() < cfg.MalformedRate {
+			line = "WS,ERR\r\n"
+		}
+		if _, err := io.WriteString(conn, line); err != nil {
+			return
+		}
+	}
+}
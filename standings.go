@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"PolyField/competition"
+	"PolyField/store"
+)
+
+// --- Live Standings ---
+// Every distance result posted for an event re-ranks it; the standings go to
+// the UI, the overlay and, on request, a scoreboard.
+const standingsEvent = "standings-update"
+
+type Standing = competition.Standing
+type EventStandings struct {
+	EventID   string     `json:"eventId"`
+	Standings []Standing `json:"standings"`
+}
+
+// horizontalEvent keeps entries in start order; athletes the event details
+// did not list are added as their first result arrives. event is the last
+// details fetched, if any.
+type horizontalEvent struct {
+	event   Event
+	entries []*competition.Entry
+	flights []Flight
+}
+
+// entry must be called with competitionMux held.
+func (a *App) entry(eventID, bib string) *competition.Entry {
+	he, ok := a.horizontals[eventID]
+	if !ok {
+		he = &horizontalEvent{}
+		a.horizontals[eventID] = he
+	}
+	for _, e := range he.entries {
+		if e.Bib == bib {
+			return e
+		}
+	}
+	e := &competition.Entry{Bib: bib}
+	he.entries = append(he.entries, e)
+	return e
+}
+
+// registerEntries lists the event's athletes in start order, keeping any
+// results already recorded.
+func (a *App) registerEntries(event Event) {
+	athletes := append([]Athlete(nil), event.Athletes...)
+	sort.SliceStable(athletes, func(i, j int) bool { return athletes[i].Order < athletes[j].Order })
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	for _, ath := range athletes {
+		a.entry(event.ID, ath.Bib)
+	}
+	if he, ok := a.horizontals[event.ID]; ok {
+		he.event = event
+	}
+	if he, ok := a.horizontals[event.ID]; ok && len(event.Flights) > 0 {
+		if err := competition.ValidateFlights(event.Flights, he.bibs()); err != nil {
+			log.Printf("Ignoring flights for event %s from the server: %v", event.ID, err)
+			return
+		}
+		he.flights = event.Flights
+	}
+}
+
+// standings must be called with competitionMux held.
+func (a *App) standings(eventID string) []Standing {
+	he, ok := a.horizontals[eventID]
+	if !ok {
+		return []Standing{}
+	}
+	return competition.RankHorizontal(he.entryValues())
+}
+
+// recordResult keeps the card in the open competition and re-ranks the event
+// for a posted series. Vertical jump cards are ranked by their own
+// competition.
+func (a *App) recordResult(payload ResultPayload) {
+	a.updateCompetition(func(c *store.Competition) { c.PutResult(payload) })
+	if len(payload.Heights) > 0 {
+		return
+	}
+	a.competitionMux.Lock()
+	a.entry(payload.EventID, payload.AthleteBib).Series = payload.Series
+	standings := a.standings(payload.EventID)
+	a.competitionMux.Unlock()
+	a.publishStandings(payload.EventID, standings)
+}
+func (a *App) publishStandings(eventID string, standings []Standing) {
+	a.emitEvent(standingsEvent, EventStandings{EventID: eventID, Standings: standings})
+	a.setOverlayStandings(eventID, standings)
+}
+func (a *App) GetStandings(eventID string) []Standing {
+	a.competitionMux.Lock()
+	defer a.competitionMux.Unlock()
+	return a.standings(eventID)
+}
+
+// SetAthleteStatus records a judge's DNS, DNF or r (retired) for bib; an
+// empty status clears it.
+func (a *App) SetAthleteStatus(eventID, bib, status string) ([]Standing, error) {
+	switch status {
+	case "", competition.StatusDNS, competition.StatusDNF, competition.StatusRetired:
+	default:
+		return nil, fmt.Errorf("invalid athlete status '%s': use DNS, DNF or r", status)
+	}
+	a.competitionMux.Lock()
+	a.entry(eventID, bib).Status = status
+	standings := a.standings(eventID)
+	a.competitionMux.Unlock()
+	a.updateCompetition(func(c *store.Competition) { c.PutStatus(store.Status{EventID: eventID, Bib: bib, Status: status}) })
+	a.publishStandings(eventID, standings)
+	return standings, nil
+}
+
+// ShowStandingsOnScoreboard queues the top count places as one line each.
+func (a *App) ShowStandingsOnScoreboard(deviceID, eventID string, count int) (int64, error) {
+	var lines []string
+	for _, st := range a.GetStandings(eventID) {
+		if st.Rank == 0 || len(lines) == count {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d %s %s", st.Rank, st.Bib, st.Best))
+	}
+	if len(lines) == 0 {
+		return 0, fmt.Errorf("no ranked athletes in event '%s' yet", eventID)
+	}
+	return a.QueueScoreboardMessage(deviceID, ScoreboardKindStandings, ScoreboardMessage{Lines: lines})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStandingsFollowPostedResults(t *testing.T) {
+	event := Event{ID: "sp-m", Name: "Shot Put Men", Athletes: []Athlete{{Bib: "3", Order: 2, Name: "Cole"}, {Bib: "1", Order: 1, Name: "Ames"}, {Bib: "2", Order: 3, Name: "Burr"}}}
+	srv := newFakePolyFieldServer(t, []Event{event})
+	a := newTestApp(t)
+	host, port := srv.hostPort()
+	if _, err := a.FetchEventDetails(host, port, "sp-m"); err != nil {
+		t.Fatal(err)
+	}
+	a.SetOverlayEvent(event)
+	post := func(bib string, marks ...string) {
+		t.Helper()
+		var series []Performance
+		for i, m := range marks {
+			series = append(series, Performance{Attempt: i + 1, Mark: m, Unit: "m", Valid: m != "FOUL"})
+		}
+		if err := a.PostResult(host, port, ResultPayload{EventID: "sp-m", AthleteBib: bib, Series: series}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	post("1", "14.10", "FOUL")
+	post("3", "14.10", "13.95")
+	if _, err := a.SetAthleteStatus("sp-m", "2", "DNS"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetAthleteStatus("sp-m", "2", "DQ"); err == nil {
+		t.Fatal("unknown status accepted")
+	}
+	if err := a.PostResult(host, port, ResultPayload{EventID: "sp-m", AthleteBib: "2", Series: []Performance{{Attempt: 1, Status: "dq"}}}); err == nil {
+		t.Fatal("disqualification without a rule reference accepted")
+	}
+
+	want := []Standing{
+		{Rank: 1, Bib: "3", Best: "14.10", Marks: []string{"14.10", "13.95"}},
+		{Rank: 2, Bib: "1", Best: "14.10", Marks: []string{"14.10"}},
+		{Bib: "2", Status: "DNS"},
+	}
+	if got := a.GetStandings("sp-m"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("standings %+v, want %+v", got, want)
+	}
+	overlay := a.GetOverlayState().Standings
+	if len(overlay) != 3 || overlay[0].Name != "Cole" || overlay[2].Status != "DNS" {
+		t.Fatalf("overlay standings %+v", overlay)
+	}
+}
+
+func TestNoMarkOnlyOnceAttemptsAreTaken(t *testing.T) {
+	a := newTestApp(t)
+	a.registerEvent(Event{ID: "lj-w", Rules: EventRules{Attempts: 4}, Athletes: []Athlete{{Bib: "9", Order: 1}}})
+	fouls := func(n int) []Performance {
+		series := make([]Performance, 4)
+		for i := range series {
+			series[i] = Performance{Attempt: i + 1, Unit: "m", Valid: true}
+			if i < n {
+				series[i] = Performance{Attempt: i + 1, Mark: "FOUL", Unit: "m"}
+			}
+		}
+		return series
+	}
+	a.submitResult(ResultPayload{EventID: "lj-w", AthleteBib: "9", Series: fouls(3)})
+	if got := a.GetStandings("lj-w"); got[0].Status != "" {
+		t.Fatalf("NM with an attempt to come: %+v", got)
+	}
+	a.submitResult(ResultPayload{EventID: "lj-w", AthleteBib: "9", Series: fouls(4)})
+	if got := a.GetStandings("lj-w"); got[0].Status != "NM" {
+		t.Fatalf("standings after four fouls %+v", got)
+	}
+}
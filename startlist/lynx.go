@@ -0,0 +1,59 @@
+package startlist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"PolyField/api"
+)
+
+// readLynx reads a Lynx event file (lynx.evt) as exported by meet managers:
+// a line per event of number, round, heat and name, followed by lines with
+// an empty first field for each athlete: ID, lane, last name, first name and
+// affiliation. Field events use the lane column for the start order.
+func readLynx(r io.Reader) ([]api.Event, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	var events []api.Event
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Lynx event file: %w", err)
+		}
+		for len(rec) < 6 {
+			rec = append(rec, "")
+		}
+		if strings.TrimSpace(strings.Join(rec, "")) == "" || strings.HasPrefix(rec[0], ";") {
+			continue
+		}
+		if rec[0] != "" {
+			id := rec[0]
+			if round, heat := strings.TrimSpace(rec[1]), strings.TrimSpace(rec[2]); !(round == "" || round == "1") || !(heat == "" || heat == "1") {
+				id = fmt.Sprintf("%s-%s-%s", rec[0], round, heat)
+			}
+			events = append(events, api.Event{ID: id, Name: strings.TrimSpace(rec[3])})
+			continue
+		}
+		if len(events) == 0 {
+			return nil, fmt.Errorf("line %d: athlete before any event", line)
+		}
+		order, err := number(strings.TrimSpace(rec[2]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid lane '%s'", line, rec[2])
+		}
+		ev := &events[len(events)-1]
+		ev.Athletes = append(ev.Athletes, api.Athlete{
+			Bib:   strings.TrimSpace(rec[1]),
+			Order: order,
+			Name:  strings.TrimSpace(strings.TrimSpace(rec[4]) + " " + strings.TrimSpace(rec[3])),
+			Club:  strings.TrimSpace(rec[5]),
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,109 @@
+// Package startlist reads start lists from files for events run without a
+// results server: CSV, Excel (.xlsx), JSON in the server's event format, and
+// the Lynx event files (.evt) meet-management software exports.
+package startlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"PolyField/api"
+)
+
+// Read parses a start list, choosing the format from the file name. Files
+// that carry no event of their own (CSV and Excel without an event column)
+// fill in defaultEvent. Every event is validated.
+func Read(name string, r io.Reader, defaultEvent api.Event) ([]api.Event, error) {
+	var events []api.Event
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".csv":
+		var rows [][]string
+		if rows, err = readCSV(r); err == nil {
+			events, err = fromRows(rows, defaultEvent)
+		}
+	case ".xlsx":
+		var rows [][]string
+		if rows, err = readXLSX(r); err == nil {
+			events, err = fromRows(rows, defaultEvent)
+		}
+	case ".json":
+		events, err = readJSON(r)
+	case ".evt":
+		events, err = readLynx(r)
+	default:
+		return nil, fmt.Errorf("cannot import '%s' files: use .csv, .xlsx, .json or .evt", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no events in %s", name)
+	}
+	for i := range events {
+		if err := Validate(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// readJSON takes one event or a list of them.
+func readJSON(r io.Reader) ([]api.Event, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []api.Event
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &events)
+	} else {
+		var event api.Event
+		err = json.Unmarshal(data, &event)
+		events = []api.Event{event}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid start list JSON: %w", err)
+	}
+	return events, nil
+}
+
+// Validate checks that the event has an ID and that bibs and start order
+// numbers are present and unique. A list without any order numbers is
+// numbered in the order given.
+func Validate(event *api.Event) error {
+	if strings.TrimSpace(event.ID) == "" {
+		return fmt.Errorf("event '%s' has no ID", event.Name)
+	}
+	numbered := false
+	for _, ath := range event.Athletes {
+		numbered = numbered || ath.Order != 0
+	}
+	bibs := make(map[string]bool, len(event.Athletes))
+	orders := make(map[int]string, len(event.Athletes))
+	for i := range event.Athletes {
+		ath := &event.Athletes[i]
+		ath.Bib = strings.TrimSpace(ath.Bib)
+		if ath.Bib == "" {
+			return fmt.Errorf("event %s: athlete %d ('%s') has no bib", event.ID, i+1, ath.Name)
+		}
+		if bibs[ath.Bib] {
+			return fmt.Errorf("event %s: bib %s appears more than once", event.ID, ath.Bib)
+		}
+		bibs[ath.Bib] = true
+		if !numbered {
+			ath.Order = i + 1
+		}
+		if ath.Order < 1 {
+			return fmt.Errorf("event %s: bib %s has no start order", event.ID, ath.Bib)
+		}
+		if other, ok := orders[ath.Order]; ok {
+			return fmt.Errorf("event %s: bibs %s and %s share start order %d", event.ID, other, ath.Bib, ath.Order)
+		}
+		orders[ath.Order] = ath.Bib
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package startlist
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"PolyField/api"
+)
+
+func TestReadCSV(t *testing.T) {
+	csv := "Event ID,Event Name,Bib,Start Order,First Name,Last Name,Club,Age,PB\n" +
+		"sp-m,Shot Put Men,12,2,Sam,Ode,KACPH,41,14.20\n" +
+		"sp-m,Shot Put Men,7,1,\"Lee, Jr\",Park,,,\n" +
+		",,,,,,,,\n" +
+		"lj-w,Long Jump Women,3,1,Ana,Ruiz,,,\n"
+	events, err := Read("entries.csv", strings.NewReader(csv), api.Event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []api.Event{
+		{ID: "sp-m", Name: "Shot Put Men", Athletes: []api.Athlete{
+			{Bib: "12", Order: 2, Name: "Sam Ode", Club: "KACPH", Age: 41, PB: "14.20"},
+			{Bib: "7", Order: 1, Name: "Lee, Jr Park"}}},
+		{ID: "lj-w", Name: "Long Jump Women", Athletes: []api.Athlete{{Bib: "3", Order: 1, Name: "Ana Ruiz"}}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events\n%+v\nwant\n%+v", events, want)
+	}
+}
+
+func TestReadCSVIntoDefaultEvent(t *testing.T) {
+	events, err := Read("list.CSV", strings.NewReader("bib,name\n5,A\n9,B\n"), api.Event{ID: "dt-w", Name: "Discus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without order numbers the list order is the start order.
+	if len(events) != 1 || events[0].ID != "dt-w" || events[0].Athletes[1].Order != 2 {
+		t.Fatalf("events %+v", events)
+	}
+}
+
+func TestValidateRejectsClashes(t *testing.T) {
+	for name, tc := range map[string]struct{ csv, eventID string }{
+		"duplicate bib":   {"bib,order\n5,1\n5,2\n", "e"},
+		"duplicate order": {"bib,order\n5,1\n6,1\n", "e"},
+		"missing order":   {"bib,order\n5,1\n6,\n", "e"},
+		"missing bib":     {"bib,name\n,A\n", "e"},
+		"no bib column":   {"name\nA\n", "e"},
+		"no event ID":     {"bib\n5\n", ""},
+	} {
+		if _, err := Read("x.csv", strings.NewReader(tc.csv), api.Event{ID: tc.eventID}); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	one := `{"id": "hj", "name": "High Jump", "athletes": [{"bib": "1", "order": 1}]}`
+	events, err := Read("hj.json", strings.NewReader(one), api.Event{})
+	if err != nil || len(events) != 1 || events[0].Athletes[0].Bib != "1" {
+		t.Fatalf("single event: %+v, %v", events, err)
+	}
+	events, err = Read("all.json", strings.NewReader("["+one+","+strings.Replace(one, `"hj"`, `"pv"`, 1)+"]"), api.Event{})
+	if err != nil || len(events) != 2 || events[1].ID != "pv" {
+		t.Fatalf("event list: %+v, %v", events, err)
+	}
+}
+
+func TestReadLynx(t *testing.T) {
+	evt := "; exported start list\n" +
+		"14,1,1,Javelin Men\n" +
+		",101,2,Smith,John,KACPH\n" +
+		",102,1,Jones,Ed,\n" +
+		"15,2,1,Hammer Women\n" +
+		",201,1,Ode,Kim,Harriers\n"
+	events, err := Read("lynx.evt", strings.NewReader(evt), api.Event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []api.Event{
+		{ID: "14", Name: "Javelin Men", Athletes: []api.Athlete{{Bib: "101", Order: 2, Name: "John Smith", Club: "KACPH"}, {Bib: "102", Order: 1, Name: "Ed Jones"}}},
+		{ID: "15-2-1", Name: "Hammer Women", Athletes: []api.Athlete{{Bib: "201", Order: 1, Name: "Kim Ode", Club: "Harriers"}}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events\n%+v\nwant\n%+v", events, want)
+	}
+}
+
+// testWorkbook builds a minimal workbook with shared, inline and numeric cells
+// and a gap in the columns.
+func testWorkbook(t *testing.T) []byte {
+	t.Helper()
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Entries" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/entries.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>Bib</t></si><si><t>Name</t></si><si><r><t>Ada </t></r><r><t>Byron</t></r></si><si><t>SB</t></si></sst>`,
+		"xl/worksheets/entries.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>3</v></c></row>` +
+			`<row r="2"><c r="A2"><v>17</v></c><c r="B2" t="s"><v>2</v></c><c r="D2"><v>14.499999999999998</v></c></row>` +
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>A9</t></is></c><c r="B3" t="inlineStr"><is><t>Bo</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	events, err := Read("entries.xlsx", bytes.NewReader(testWorkbook(t)), api.Event{ID: "sp-w"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []api.Athlete{{Bib: "17", Order: 1, Name: "Ada Byron", SB: "14.5"}, {Bib: "A9", Order: 2, Name: "Bo"}}
+	if len(events) != 1 || !reflect.DeepEqual(events[0].Athletes, want) {
+		t.Fatalf("events %+v", events)
+	}
+	if _, err := Read("broken.xlsx", strings.NewReader("not a zip"), api.Event{ID: "x"}); err == nil {
+		t.Fatal("broken workbook accepted")
+	}
+}
@@ -0,0 +1,109 @@
+package startlist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"PolyField/api"
+)
+
+// --- Tabular Start Lists ---
+// CSV and Excel lists have a header row. Columns are matched by name, case
+// and spacing aside; only bib is required. Rows with an event column are
+// grouped into events in the order they first appear.
+var columnNames = map[string]string{
+	"event": "event", "eventid": "event", "eventname": "eventName",
+	"bib": "bib", "number": "bib", "no": "bib",
+	"order": "order", "startorder": "order", "position": "order",
+	"name": "name", "firstname": "first", "first": "first", "lastname": "last", "last": "last", "surname": "last",
+	"club": "club", "team": "club", "affiliation": "club",
+	"age": "age", "pb": "pb", "sb": "sb",
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid start list CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// fromRows builds events from a header row and athlete rows.
+func fromRows(rows [][]string, defaultEvent api.Event) ([]api.Event, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("start list is empty")
+	}
+	columns := make(map[string]int)
+	for i, h := range rows[0] {
+		key := strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimPrefix(h, "\ufeff")))
+		if field, ok := columnNames[key]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["bib"]; !ok {
+		return nil, fmt.Errorf("start list has no bib column")
+	}
+	var events []api.Event
+	index := make(map[string]int)
+	for n, row := range rows[1:] {
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		if strings.Join(row, "") == "" {
+			continue
+		}
+		line := n + 2
+		id := cell("event")
+		if id == "" {
+			id = defaultEvent.ID
+		}
+		i, ok := index[id]
+		if !ok {
+			event := defaultEvent
+			event.ID, event.Athletes = id, nil
+			if name := cell("eventName"); name != "" {
+				event.Name = name
+			}
+			index[id] = len(events)
+			events = append(events, event)
+			i = index[id]
+		}
+		ath := api.Athlete{Bib: cell("bib"), Name: cell("name"), Club: cell("club"), PB: cell("pb"), SB: cell("sb")}
+		if ath.Name == "" {
+			ath.Name = strings.TrimSpace(cell("first") + " " + cell("last"))
+		}
+		var err error
+		if ath.Order, err = number(cell("order")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid order '%s'", line, cell("order"))
+		}
+		if ath.Age, err = number(cell("age")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid age '%s'", line, cell("age"))
+		}
+		events[i].Athletes = append(events[i].Athletes, ath)
+	}
+	return events, nil
+}
+
+// number reads a whole number, allowing the "3.0" spreadsheets write; blank
+// is zero.
+func number(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != float64(int(f)) {
+		return 0, fmt.Errorf("not a whole number")
+	}
+	return int(f), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"PolyField/competition"
+	"PolyField/startlist"
+	"PolyField/store"
+)
+
+// --- Start List Import ---
+// Standalone meetings take their events from files instead of the server.
+// Imported events are tracked exactly like fetched ones.
+
+// ImportStartList reads events from a CSV, Excel, JSON or Lynx (.evt) file.
+// CSV and Excel lists without an event column go into eventID.
+func (a *App) ImportStartList(path, eventID, eventName string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	events, err := startlist.Read(filepath.Base(path), f, Event{ID: eventID, Name: eventName})
+	if err != nil {
+		return nil, err
+	}
+	// Check every event before tracking any, so a bad file changes nothing.
+	for _, event := range events {
+		if err := competition.ValidateEvent(event); err != nil {
+			return nil, fmt.Errorf("event %s: %w", event.ID, err)
+		}
+	}
+	for _, event := range events {
+		a.trackEvent(event)
+	}
+	return events, nil
+}
+
+// registerEvent checks an event's age group and implement, then tracks it.
+func (a *App) registerEvent(event Event) error {
+	if err := competition.ValidateEvent(event); err != nil {
+		return fmt.Errorf("event %s: %w", event.ID, err)
+	}
+	a.trackEvent(event)
+	return nil
+}
+
+// trackEvent sets up tracking for an event's athletes, combined-events
+// scoring, age grading and bests, and keeps it in the open competition.
+func (a *App) trackEvent(event Event) {
+	a.updateCompetition(func(c *store.Competition) { c.PutEvent(event) })
+	a.registerEntries(event)
+	a.registerCombined(event)
+	a.registerMasters(event)
+	a.registerBests(event)
+}
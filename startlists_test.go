@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestImportStartListTracksEvent(t *testing.T) {
+	a := newTestApp(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shot.csv")
+	if err := os.WriteFile(path, []byte("Bib,Order,Name,PB\n21,2,Eve Hart,12.00\n20,1,Dan Hale,\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	events, err := a.ImportStartList(path, "sp-w", "Shot Put Women")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Name != "Shot Put Women" || len(events[0].Athletes) != 2 {
+		t.Fatalf("imported %+v", events)
+	}
+	if got := a.GetStandings("sp-w"); len(got) != 2 || got[0].Bib != "20" {
+		t.Fatalf("standings %+v, want start order", got)
+	}
+	if flags, _ := a.FlagMark("sp-w", "21", "12.10"); !reflect.DeepEqual(flags, []string{"PB"}) {
+		t.Fatalf("PB from the start list not used: %v", flags)
+	}
+
+	dup := filepath.Join(dir, "dup.csv")
+	os.WriteFile(dup, []byte("bib,order\n5,1\n5,2\n"), 0644)
+	if _, err := a.ImportStartList(dup, "lj-m", ""); err == nil {
+		t.Fatal("duplicate bibs imported")
+	}
+}
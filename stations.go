@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"PolyField/devices"
+)
+
+// --- Named Devices & Stations ---
+
+// A station is one measuring position, typically one throwing circle, made up
+// of an EDM plus the wind gauge and scoreboard that serve it.
+type Station struct {
+	Name       string `json:"name"`
+	EDM        string `json:"edm"`
+	Wind       string `json:"wind,omitempty"`
+	Scoreboard string `json:"scoreboard,omitempty"`
+	EventID    string `json:"eventId,omitempty"`
+	// Athlete is the bib now up at this station's event.
+	Athlete string `json:"athlete,omitempty"`
+}
+type DeviceInfo struct {
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	ConnectionType string `json:"connectionType"`
+	Address        string `json:"address"`
+	Station        string `json:"station,omitempty"`
+	Calibrated     bool   `json:"calibrated"`
+}
+
+func (a *App) ListDevices() []DeviceInfo {
+	a.devicesMux.RLock()
+	defer a.devicesMux.RUnlock()
+	infos := make([]DeviceInfo, 0, len(a.devices))
+	for id, dev := range a.devices {
+		info := DeviceInfo{ID: id, Type: dev.Type, ConnectionType: dev.ConnectionType, Address: dev.Address}
+		if st := a.stationForDevice(id); st != nil {
+			info.Station = st.Name
+		}
+		if cal, ok := a.calibrations.Get(id); ok {
+			info.Calibrated = cal.IsCentreSet
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+func (a *App) ListStations() []Station {
+	a.devicesMux.RLock()
+	defer a.devicesMux.RUnlock()
+	stations := make([]Station, 0, len(a.stations))
+	for _, st := range a.stations {
+		stations = append(stations, *st)
+	}
+	sort.Slice(stations, func(i, j int) bool { return stations[i].Name < stations[j].Name })
+	return stations
+}
+func (a *App) SaveStation(station Station) error {
+	if strings.TrimSpace(station.Name) == "" {
+		return fmt.Errorf("station name is required")
+	}
+	for _, check := range []struct{ id, devType string }{{station.EDM, "edm"}, {station.Wind, "wind"}, {station.Scoreboard, "scoreboard"}} {
+		if check.id == "" {
+			continue
+		}
+		if devType, err := devices.TypeForID(check.id); err != nil || devType != check.devType {
+			return fmt.Errorf("'%s' is not a %s device", check.id, check.devType)
+		}
+	}
+	if station.EDM == "" {
+		return fmt.Errorf("station '%s' needs an EDM", station.Name)
+	}
+	a.devicesMux.Lock()
+	defer a.devicesMux.Unlock()
+	for name, other := range a.stations {
+		if name != station.Name && other.EDM == station.EDM {
+			return fmt.Errorf("EDM '%s' already belongs to station '%s'", station.EDM, name)
+		}
+	}
+	a.stations[station.Name] = &station
+	return nil
+}
+func (a *App) DeleteStation(name string) error {
+	a.devicesMux.Lock()
+	defer a.devicesMux.Unlock()
+	if _, ok := a.stations[name]; !ok {
+		return fmt.Errorf("station '%s' not found", name)
+	}
+	delete(a.stations, name)
+	return nil
+}
+func (a *App) BindStationEvent(name, eventID string) error {
+	a.devicesMux.Lock()
+	defer a.devicesMux.Unlock()
+	st, ok := a.stations[name]
+	if !ok {
+		return fmt.Errorf("station '%s' not found", name)
+	}
+	if st.EventID != eventID {
+		st.Athlete = ""
+	}
+	st.EventID = eventID
+	return nil
+}
+
+// SetStationAthlete names the athlete now up at a station, so its marks are
+// flagged and recorded against them.
+func (a *App) SetStationAthlete(name, bib string) error {
+	a.devicesMux.Lock()
+	defer a.devicesMux.Unlock()
+	st, ok := a.stations[name]
+	if !ok {
+		return fmt.Errorf("station '%s' not found", name)
+	}
+	if st.EventID == "" {
+		return fmt.Errorf("station '%s' has no event", name)
+	}
+	st.Athlete = bib
+	return nil
+}
+
+// stationForDevice finds the station a device belongs to. Callers must hold
+// devicesMux.
+func (a *App) stationForDevice(deviceID string) *Station {
+	for _, st := range a.stations {
+		if st.EDM == deviceID || st.Wind == deviceID || st.Scoreboard == deviceID {
+			return st
+		}
+	}
+	return nil
+}
+
+// measuredAthlete is the event and athlete a device is measuring. A station
+// bound to an event has its own athlete, empty until one is named; any other
+// device measures the live event and athlete on the overlay.
+func (a *App) measuredAthlete(deviceID string) (eventID, bib string) {
+	a.devicesMux.RLock()
+	if st := a.stationForDevice(deviceID); st != nil && st.EventID != "" {
+		eventID, bib = st.EventID, st.Athlete
+	}
+	a.devicesMux.RUnlock()
+	if eventID != "" {
+		return eventID, bib
+	}
+	state := a.GetOverlayState()
+	if state.Athlete != nil {
+		bib = state.Athlete.Bib
+	}
+	return state.EventID, bib
+}
+
+// queueStationScoreboard queues a value for the scoreboard serving the
+// station that deviceID belongs to, falling back to the default "scoreboard".
+func (a *App) queueStationScoreboard(deviceID, kind, value string) {
+	a.devicesMux.RLock()
+	scoreboardID := "scoreboard"
+	if st := a.stationForDevice(deviceID); st != nil && st.Scoreboard != "" {
+		scoreboardID = st.Scoreboard
+	}
+	a.devicesMux.RUnlock()
+	if _, err := a.QueueScoreboardMessage(scoreboardID, kind, ScoreboardMessage{Lines: []string{value}}); err != nil {
+		log.Printf("Could not queue scoreboard update for %s: %v", scoreboardID, err)
+	}
+}
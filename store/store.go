@@ -0,0 +1,241 @@
+// Package store keeps standalone competitions on disk so a meeting can be
+// closed and resumed. Each competition is one JSON file in the store's
+// directory, rewritten in full on every change; a meeting's worth of data is
+// small enough that this stays quick, and the file can be read by hand.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"PolyField/api"
+	"PolyField/calibration"
+)
+
+const fileExt = ".json"
+
+// Competition is everything recorded at one meeting. Results hold each
+// athlete's latest card per event, which carries all their attempts.
+type Competition struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	UpdatedAt    time.Time           `json:"updatedAt"`
+	SyncedAt     *time.Time          `json:"syncedAt,omitempty"`
+	Events       []api.Event         `json:"events"`
+	Verticals    []Vertical          `json:"verticals,omitempty"`
+	Results      []api.ResultPayload `json:"results"`
+	Statuses     []Status            `json:"statuses,omitempty"`
+	Measurements []Measurement       `json:"measurements"`
+	Calibrations []calibration.Data  `json:"calibrations"`
+}
+
+// Vertical is how a high jump or pole vault was set up, so it can be rebuilt
+// from the athletes' cards. Retired lists the athletes who withdrew, and
+// JumpOff is set once a jump-off for first place has started; its attempts
+// are on the cards.
+type Vertical struct {
+	EventID    string   `json:"eventId"`
+	Discipline string   `json:"discipline"`
+	Heights    []int    `json:"heights"`
+	Retired    []string `json:"retired,omitempty"`
+	JumpOff    bool     `json:"jumpOff,omitempty"`
+}
+
+// Status is a judge's DNS, DNF or r for an athlete in a distance event.
+type Status struct {
+	EventID string `json:"eventId"`
+	Bib     string `json:"bib"`
+	Status  string `json:"status"`
+}
+
+// Measurement is one reading taken by a device, with the athlete on the
+// overlay at the time if there was one.
+type Measurement struct {
+	DeviceID string    `json:"deviceId"`
+	Kind     string    `json:"kind"`
+	Value    string    `json:"value"`
+	EventID  string    `json:"eventId,omitempty"`
+	Bib      string    `json:"bib,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// Summary lists a competition without its contents.
+type Summary struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	SyncedAt  *time.Time `json:"syncedAt,omitempty"`
+	Events    int        `json:"events"`
+	Results   int        `json:"results"`
+	// Error says why a competition file could not be read; such a file is
+	// listed by ID only and cannot be resumed until it is repaired.
+	Error string `json:"error,omitempty"`
+}
+
+func (c *Competition) Summary() Summary {
+	return Summary{ID: c.ID, Name: c.Name, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt, SyncedAt: c.SyncedAt, Events: len(c.Events), Results: len(c.Results)}
+}
+
+// PutEvent adds the event or replaces the one with its ID.
+func (c *Competition) PutEvent(event api.Event) {
+	for i := range c.Events {
+		if c.Events[i].ID == event.ID {
+			c.Events[i] = event
+			return
+		}
+	}
+	c.Events = append(c.Events, event)
+}
+func (c *Competition) PutVertical(v Vertical) {
+	for i := range c.Verticals {
+		if c.Verticals[i].EventID == v.EventID {
+			c.Verticals[i] = v
+			return
+		}
+	}
+	c.Verticals = append(c.Verticals, v)
+}
+
+// PutResult keeps the latest card for the payload's event and athlete.
+func (c *Competition) PutResult(payload api.ResultPayload) {
+	for i := range c.Results {
+		if c.Results[i].EventID == payload.EventID && c.Results[i].AthleteBib == payload.AthleteBib {
+			c.Results[i] = payload
+			return
+		}
+	}
+	c.Results = append(c.Results, payload)
+}
+
+// PutStatus sets the athlete's status; an empty status removes it.
+func (c *Competition) PutStatus(st Status) {
+	for i := range c.Statuses {
+		if c.Statuses[i].EventID == st.EventID && c.Statuses[i].Bib == st.Bib {
+			c.Statuses = append(c.Statuses[:i], c.Statuses[i+1:]...)
+			break
+		}
+	}
+	if st.Status != "" {
+		c.Statuses = append(c.Statuses, st)
+	}
+}
+func (c *Competition) PutCalibration(d calibration.Data) {
+	for i := range c.Calibrations {
+		if c.Calibrations[i].DeviceID == d.DeviceID {
+			c.Calibrations[i] = d
+			return
+		}
+	}
+	c.Calibrations = append(c.Calibrations, d)
+}
+
+// Store is a directory of competition files. It is safe for concurrent use.
+type Store struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// Open uses dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+var unsafeIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create starts and saves a new competition. Its ID is the creation time
+// followed by the name, so files sort by date.
+func (s *Store) Create(name string, now time.Time) (*Competition, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("competition needs a name")
+	}
+	id := now.UTC().Format("20060102-150405") + "-" + strings.Trim(unsafeIDChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(s.path(id)); err == nil {
+		return nil, fmt.Errorf("competition '%s' already exists", id)
+	}
+	c := &Competition{ID: id, Name: name, CreatedAt: now, UpdatedAt: now,
+		Events: []api.Event{}, Results: []api.ResultPayload{}, Measurements: []Measurement{}, Calibrations: []calibration.Data{}}
+	return c, s.save(c)
+}
+
+// List returns every competition, most recently updated first, with any
+// unreadable files last.
+func (s *Store) List() ([]Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names, err := filepath.Glob(filepath.Join(s.dir, "*"+fileExt))
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]Summary, 0, len(names))
+	for _, name := range names {
+		id := strings.TrimSuffix(filepath.Base(name), fileExt)
+		c, err := s.load(id)
+		if err != nil {
+			summaries = append(summaries, Summary{ID: id, Error: err.Error()})
+			continue
+		}
+		summaries = append(summaries, c.Summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt) })
+	return summaries, nil
+}
+func (s *Store) Load(id string) (*Competition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+// Save writes c, stamping UpdatedAt.
+func (s *Store) Save(c *Competition, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.UpdatedAt = now
+	return s.save(c)
+}
+func (s *Store) path(id string) string { return filepath.Join(s.dir, id+fileExt) }
+func (s *Store) load(id string) (*Competition, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid competition ID '%s'", id)
+	}
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no competition '%s'", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Competition
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("competition '%s' is damaged: %w", id, err)
+	}
+	return &c, nil
+}
+
+// save writes to a temporary file first so a crash mid-write cannot leave a
+// half-written competition.
+func (s *Store) save(c *Competition) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path(c.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(c.ID))
+}
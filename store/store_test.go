@@ -0,0 +1,75 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"PolyField/api"
+	"PolyField/calibration"
+)
+
+func TestCompetitionRoundTrip(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 5, 9, 10, 0, 0, 0, time.UTC)
+	c, err := s.Create("Club Open: Throws", created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != "20260509-100000-club-open-throws" {
+		t.Fatalf("ID %q", c.ID)
+	}
+	if _, err := s.Create("Club Open: Throws", created); err == nil {
+		t.Fatal("duplicate competition created")
+	}
+
+	c.PutEvent(api.Event{ID: "sp", Name: "Shot"})
+	c.PutEvent(api.Event{ID: "sp", Name: "Shot Put"})
+	c.PutResult(api.ResultPayload{EventID: "sp", AthleteBib: "1", Series: []api.Performance{{Attempt: 1, Mark: "FOUL"}}})
+	c.PutResult(api.ResultPayload{EventID: "sp", AthleteBib: "1", Series: []api.Performance{{Attempt: 1, Mark: "FOUL"}, {Attempt: 2, Mark: "10.00", Valid: true}}})
+	c.PutCalibration(calibration.Default("edm"))
+	c.PutStatus(Status{EventID: "sp", Bib: "2", Status: "DNS"})
+	c.PutStatus(Status{EventID: "sp", Bib: "3", Status: "DNF"})
+	c.PutStatus(Status{EventID: "sp", Bib: "2", Status: ""})
+	c.PutVertical(Vertical{EventID: "hj", Discipline: "HJ", Heights: []int{150}})
+	c.Measurements = append(c.Measurements, Measurement{DeviceID: "edm", Kind: "throw", Value: "10.00 m", At: created})
+	if err := s.Save(c, created.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.Load(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, c) {
+		t.Fatalf("loaded\n%+v\nwant\n%+v", loaded, c)
+	}
+	if len(loaded.Events) != 1 || loaded.Events[0].Name != "Shot Put" || len(loaded.Results) != 1 || len(loaded.Results[0].Series) != 2 ||
+		len(loaded.Statuses) != 1 || loaded.Statuses[0].Bib != "3" {
+		t.Fatalf("puts did not replace: %+v", loaded)
+	}
+
+	later, _ := s.Create("Second Day", created.Add(24*time.Hour))
+	list, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != later.ID || list[1].Results != 1 {
+		t.Fatalf("list %+v", list)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, "20260511-090000-half-written.json"), []byte(`{"id": "20260511`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list, err = s.List()
+	if err != nil || len(list) != 3 || list[2].ID != "20260511-090000-half-written" || list[2].Error == "" || list[0].ID != later.ID {
+		t.Fatalf("list with a damaged file %+v, %v", list, err)
+	}
+	if _, err := s.Load("../etc/passwd"); err == nil {
+		t.Fatal("path outside the store accepted")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"PolyField/competition"
+)
+
+// --- Vertical Jumps ---
+// High jump and pole vault run on bar heights instead of distance marks.
+// Heights are metres at this boundary and whole centimetres inside. Every
+// recorded attempt sends the athlete's full card to the results server.
+type verticalEvent struct {
+	event Event
+	jump  *competition.VerticalJump
+}
+type VerticalAthleteCard struct {
+	Bib     string         `json:"bib"`
+	Name    string         `json:"name"`
+	Club    string         `json:"club,omitempty"`
+	Heights []HeightRecord `json:"heights"`
+}
+type VerticalJumpState struct {
+	EventID    string                         `json:"eventId"`
+	Discipline string                         `json:"discipline"`
+	Heights    []string                       `json:"heights"`
+	Athletes   []VerticalAthleteCard          `json:"athletes"`
+	Standings  []competition.VerticalStanding `json:"standings"`
+	// JumpOffHeight is set while a jump-off is in progress.
+	JumpOffHeight string `json:"jumpOffHeight,omitempty"`
+}
+
+// StartVerticalJump begins a high jump ("HJ") or pole vault ("PV") for event
+// with the announced bar progression, athletes in start order.
+func (a *App) StartVerticalJump(event Event, discipline string, heights []float64) (*VerticalJumpState, error) {
+	athletes := append([]Athlete(nil), event.Athletes...)
+	sort.SliceStable(athletes, func(i, j int) bool { return athletes[i].Order < athletes[j].Order })
+	bibs := make([]string, len(athletes))
+	for i, ath := range athletes {
+		bibs[i] = ath.Bib
+	}
+	cms := make([]int, len(heights))
+	for i, h := range heights {
+		cms[i] = competition.Centimetres(h)
+	}
+	jump, err := competition.NewVerticalJump(discipline, cms, bibs)
+	if err != nil {
+		return nil, err
+	}
+	a.registerCombined(event)
+	event.Athletes = athletes
+	ve := &verticalEvent{event: event, jump: jump}
+	a.competitionMux.Lock()
+	a.verticals[event.ID] = ve
+	state := ve.state()
+	a.competitionMux.Unlock()
+	a.saveVertical(event.ID)
+	return state, nil
+}
+func (a *App) GetVerticalJump(eventID string) (*VerticalJumpState, error) {
+	return a.updateVertical(eventID, "", func(*competition.VerticalJump) error { return nil })
+}
+func (a *App) AddVerticalHeight(eventID string, height float64) (*VerticalJumpState, error) {
+	state, err := a.updateVertical(eventID, "", func(j *competition.VerticalJump) error {
+		return j.AddHeight(competition.Centimetres(height))
+	})
+	if err == nil {
+		a.saveVertical(eventID)
+	}
+	return state, err
+}
+
+// RecordVerticalAttempt records O, X or - for bib at height and submits the
+// athlete's card.
+func (a *App) RecordVerticalAttempt(eventID, bib string, height float64, result string) (*VerticalJumpState, error) {
+	return a.updateVertical(eventID, bib, func(j *competition.VerticalJump) error {
+		return j.Record(bib, competition.Centimetres(height), result)
+	})
+}
+func (a *App) RetireVerticalAthlete(eventID, bib string) (*VerticalJumpState, error) {
+	state, err := a.updateVertical(eventID, "", func(j *competition.VerticalJump) error { return j.Retire(bib) })
+	if err == nil {
+		a.saveVertical(eventID)
+	}
+	return state, err
+}
+func (a *App) StartVerticalJumpOff(eventID string) (*VerticalJumpState, error) {
+	state, err := a.updateVertical(eventID, "", func(j *competition.VerticalJump) error { return j.StartJumpOff() })
+	if err == nil {
+		a.saveVertical(eventID)
+	}
+	return state, err
+}
+func (a *App) RecordVerticalJumpOff(eventID, bib, result string) (*VerticalJumpState, error) {
+	return a.updateVertical(eventID, bib, func(j *competition.VerticalJump) error { return j.RecordJumpOff(bib, result) })
+}
+
+// updateVertical applies change under the lock and, when submitBib is set,
+// sends that athlete's card once the lock is released. A failed submission
+// is queued for retry and does not undo the attempt.
+func (a *App) updateVertical(eventID, submitBib string, change func(j *competition.VerticalJump) error) (*VerticalJumpState, error) {
+	a.competitionMux.Lock()
+	ve, ok := a.verticals[eventID]
+	if !ok {
+		a.competitionMux.Unlock()
+		return nil, fmt.Errorf("no vertical jump in progress for event '%s'", eventID)
+	}
+	if err := change(ve.jump); err != nil {
+		a.competitionMux.Unlock()
+		return nil, err
+	}
+	state := ve.state()
+	ranking := ve.jump.Ranking()
+	var payload *ResultPayload
+	if submitBib != "" {
+		records, _ := ve.jump.Records(submitBib)
+		payload = &ResultPayload{EventID: eventID, AthleteBib: submitBib, Series: []Performance{}, Heights: records}
+	}
+	a.competitionMux.Unlock()
+	a.publishStandings(eventID, ranking)
+	if payload != nil {
+		if err := a.submitResult(*payload); err != nil {
+			log.Printf("Vertical jump result for bib %s not delivered: %v", submitBib, err)
+		}
+	}
+	return state, nil
+}
+
+// state must be called with competitionMux held.
+func (ve *verticalEvent) state() *VerticalJumpState {
+	s := &VerticalJumpState{EventID: ve.event.ID, Discipline: ve.jump.Discipline, Standings: ve.jump.Standings()}
+	for _, h := range ve.jump.Heights() {
+		s.Heights = append(s.Heights, competition.FormatHeight(h))
+	}
+	for _, ath := range ve.event.Athletes {
+		records, _ := ve.jump.Records(ath.Bib)
+		if records == nil {
+			records = []HeightRecord{}
+		}
+		s.Athletes = append(s.Athletes, VerticalAthleteCard{Bib: ath.Bib, Name: ath.Name, Club: ath.Club, Heights: records})
+	}
+	if h, ok := ve.jump.JumpOffHeight(); ok {
+		s.JumpOffHeight = competition.FormatHeight(h)
+	}
+	return s
+}
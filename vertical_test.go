@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVerticalJumpSubmitsCards(t *testing.T) {
+	srv := newFakePolyFieldServer(t, nil)
+	a := newTestApp(t)
+	a.SetServerAddress(srv.hostPort())
+	event := Event{ID: "hj-w", Name: "High Jump Women", Athletes: []Athlete{{Bib: "12", Order: 2}, {Bib: "7", Order: 1}}}
+	state, err := a.StartVerticalJump(event, "HJ", []float64{1.50, 1.55})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Athletes[0].Bib != "7" || !reflect.DeepEqual(state.Heights, []string{"1.50", "1.55"}) {
+		t.Fatalf("initial state %+v", state)
+	}
+	for _, r := range []string{"X", "O"} {
+		if _, err := a.RecordVerticalAttempt("hj-w", "7", 1.50, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.RecordVerticalAttempt("hj-w", "7", 1.52, "O"); err == nil {
+		t.Fatal("attempt at a height outside the progression accepted")
+	}
+	got := srv.results()
+	if len(got) != 2 {
+		t.Fatalf("server received %d results, want one per attempt", len(got))
+	}
+	if want := []HeightRecord{{Height: "1.50", Attempts: "XO"}}; got[1].AthleteBib != "7" || !reflect.DeepEqual(got[1].Heights, want) {
+		t.Fatalf("last submission %+v", got[1])
+	}
+	state, _ = a.GetVerticalJump("hj-w")
+	if st := state.Standings[0]; st.Bib != "7" || st.Rank != 1 || st.Best != 150 {
+		t.Fatalf("standings %+v", state.Standings)
+	}
+}